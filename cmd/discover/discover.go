@@ -3,7 +3,6 @@ package discover
 import (
 	"log"
 	"os"
-	"path/filepath"
 
 	ginkgoLoader "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/loader"
 	ginkgoSelector "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/selector"
@@ -47,16 +46,24 @@ func ParseTestSelectors(testSelector []string) []*ginkgoSelector.TestSelector {
 		testSelector = []string{"."}
 	}
 	var targetSelectors []*ginkgoSelector.TestSelector
+	hasExclude := false
 	for _, selector := range testSelector {
 		testSelector, err := ginkgoSelector.NewTestSelector(selector)
 		if err != nil {
 			log.Printf("Ignore invalid test selector: %s", selector)
 			continue
 		}
-		if !testSelector.IsExclude() {
+		if testSelector.IsExclude() {
+			hasExclude = true
+		} else {
 			targetSelectors = append(targetSelectors, testSelector)
 		}
 	}
+	if len(targetSelectors) == 0 && hasExclude {
+		// 仅下发了排除选择器时，从全部用例中排除
+		root, _ := ginkgoSelector.NewTestSelector(".")
+		targetSelectors = append(targetSelectors, root)
+	}
 	return targetSelectors
 }
 
@@ -78,36 +85,37 @@ func ReportTestcases(testcases []*ginkgoTestcase.TestCase, loadErrors []*sdkMode
 	return nil
 }
 
-func isFile(projectPath, selector string) bool {
-	fileInfo, err := os.Stat(filepath.Join(projectPath, selector))
-	if err != nil {
-		return false
-	}
-	return !fileInfo.IsDir()
-}
-
+// LoadTestcases 按选择器加载用例，并根据选择器的路径、行号、名称以及属性过滤加载结果
+// 相同路径只会加载一次，被多个选择器同时匹配的用例只上报一次，并在属性matchedSelector中记录首个匹配的选择器
 func LoadTestcases(projPath string, targetSelectors []*ginkgoSelector.TestSelector) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
 	var testcases []*ginkgoTestcase.TestCase
 	var loadErrors []*sdkModel.LoadError
-	loadedSelectorPath := make(map[string]struct{})
+	loadedTestcases := make(map[string][]*ginkgoTestcase.TestCase)
+	reportedTestcases := make(map[string]struct{})
 	for _, testSelector := range targetSelectors {
-		// skip the path that has been loaded
-		if _, ok := loadedSelectorPath[testSelector.Path]; ok {
-			continue
+		// 加载结果按选择器路径复用：动态解析模式下选择器为文件时虽然会加载文件所在的整个包，但引用用例的路径会被改写为该文件，
+		// 标准go测试也只保留该文件中的测试，因此不能被包内其他文件复用；包的编译与加载结果由加载缓存以及已编译的二进制文件复用
+		candidates, ok := loadedTestcases[testSelector.Path]
+		if !ok {
+			var lErrors []*sdkModel.LoadError
+			candidates, lErrors = ginkgoLoader.LoadTestCase(projPath, testSelector.Path)
+			loadedTestcases[testSelector.Path] = candidates
+			loadErrors = append(loadErrors, lErrors...)
 		}
-		if parseMode := os.Getenv("TESTSOLAR_TTP_PARSEMODE"); parseMode == "dynamic" && isFile(projPath, testSelector.Path) {
-			// 如果当前为动态解析模式，并且选择器为一个文件，则需要将文件所在包放入到已加载的包列表中，避免后续对相同包下的用例进行重复加载
-			packageDir := filepath.Dir(testSelector.Path)
-			if _, ok := loadedSelectorPath[packageDir]; ok {
+		for _, testcase := range candidates {
+			if !testSelector.Match(testcase) {
+				continue
+			}
+			if _, ok := reportedTestcases[testcase.GetSelector()]; ok {
 				continue
-			} else {
-				loadedSelectorPath[packageDir] = struct{}{}
 			}
+			reportedTestcases[testcase.GetSelector()] = struct{}{}
+			if testcase.Attributes == nil {
+				testcase.Attributes = map[string]string{}
+			}
+			testcase.Attributes["matchedSelector"] = testSelector.Value
+			testcases = append(testcases, testcase)
 		}
-		loadedSelectorPath[testSelector.Path] = struct{}{}
-		loadedTestcases, lErrors := ginkgoLoader.LoadTestCase(projPath, testSelector.Path)
-		testcases = append(testcases, loadedTestcases...)
-		loadErrors = append(loadErrors, lErrors...)
 	}
 	return testcases, loadErrors
 }
@@ -124,7 +132,11 @@ func (o *DiscoverOptions) RunDiscover(cmd *cobra.Command) error {
 	if err != nil {
 		return pkgErrors.Wrapf(err, "stat project path %s failed", projPath)
 	}
+	if err := ginkgoLoader.ClearLoadCacheIfRequired(projPath); err != nil {
+		log.Printf("clear load cache failed, err: %v", err)
+	}
 	testcases, loadErrors := LoadTestcases(projPath, targetSelectors)
+	testcases = ginkgoSelector.ExcludeTestCases(testcases, ginkgoSelector.ParseExcludeSelectors(config.TestSelectors))
 	reporter, err := sdkClient.NewReporterClient(config.FileReportPath)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create reporter")
@@ -1,7 +1,10 @@
 package discover
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/loader"
@@ -31,6 +34,15 @@ func TestParseTestSelectors(t *testing.T) {
 	testSelectors := []string{"path?name=test%20name&attr1=value%3D1"}
 	selectors := ParseTestSelectors(testSelectors)
 	assert.Len(t, selectors, 1)
+
+	selectors = ParseTestSelectors([]string{"demo", "demo/v1?exclude=true"})
+	assert.Len(t, selectors, 1)
+	assert.Equal(t, "demo", selectors[0].Path)
+
+	// 仅下发排除选择器时从全部用例中排除
+	selectors = ParseTestSelectors([]string{"demo/v1?exclude=true"})
+	assert.Len(t, selectors, 1)
+	assert.Equal(t, ".", selectors[0].Path)
 }
 
 type MockReporterClient struct{}
@@ -99,6 +111,116 @@ func TestLoadTestcases(t *testing.T) {
 	assert.Len(t, loadErrors, 0)
 }
 
+func TestLoadTestcasesByLocation(t *testing.T) {
+	LoadTestCaseMock := gomonkey.ApplyFunc(loader.LoadTestCase, func(projPath string, selectorPath string) ([]*testcase.TestCase, error) {
+		return []*testcase.TestCase{
+			{
+				Path:       "path/to/test_test.go",
+				Name:       "test01",
+				Attributes: map[string]string{"file": "path/to/test_test.go", "line": "12", "containerLocations": `["path/to/test_test.go:10"]`},
+			},
+			{
+				Path:       "path/to/test_test.go",
+				Name:       "test02",
+				Attributes: map[string]string{"file": "path/to/test_test.go", "line": "20", "containerLocations": `["path/to/test_test.go:18"]`},
+			},
+		}, nil
+	})
+	defer LoadTestCaseMock.Reset()
+	projPath, err := filepath.Abs("../../testdata")
+	assert.NoError(t, err)
+	testcases, loadErrors := LoadTestcases(projPath, ParseTestSelectors([]string{"path/to/test_test.go:12"}))
+	assert.Len(t, loadErrors, 0)
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "test01", testcases[0].Name)
+	testcases, _ = LoadTestcases(projPath, ParseTestSelectors([]string{"path/to/test_test.go:18"}))
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "test02", testcases[0].Name)
+}
+
+func TestLoadTestcasesByNameAndAttributes(t *testing.T) {
+	loadCount := 0
+	LoadTestCaseMock := gomonkey.ApplyFunc(loader.LoadTestCase, func(projPath string, selectorPath string) ([]*testcase.TestCase, error) {
+		loadCount++
+		return []*testcase.TestCase{
+			{
+				Path:       "path/to/test_test.go",
+				Name:       "Demo context case01",
+				Attributes: map[string]string{"label": `["smoke"]`},
+			},
+			{
+				Path:       "path/to/test_test.go",
+				Name:       "Demo context case02",
+				Attributes: map[string]string{"label": `["slow"]`},
+			},
+			{
+				Path:       "path/to/test_test.go",
+				Name:       "Demo contexts case03",
+				Attributes: map[string]string{},
+			},
+		}, nil
+	})
+	defer LoadTestCaseMock.Reset()
+	projPath, err := filepath.Abs("../../testdata")
+	assert.NoError(t, err)
+	// 按标签过滤
+	testcases, loadErrors := LoadTestcases(projPath, ParseTestSelectors([]string{"path/to/test_test.go?label=smoke"}))
+	assert.Len(t, loadErrors, 0)
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "Demo context case01", testcases[0].Name)
+	assert.Equal(t, "path/to/test_test.go?label=smoke", testcases[0].Attributes["matchedSelector"])
+	// 按容器名称前缀过滤，相同路径只加载一次，且重复匹配的用例只上报一次
+	loadCount = 0
+	testcases, _ = LoadTestcases(projPath, ParseTestSelectors([]string{
+		"path/to/test_test.go?Demo context",
+		"path/to/test_test.go?Demo context case02",
+	}))
+	assert.Equal(t, 1, loadCount)
+	assert.Len(t, testcases, 2)
+	assert.Equal(t, "Demo context case01", testcases[0].Name)
+	assert.Equal(t, "Demo context case02", testcases[1].Name)
+	assert.Equal(t, "path/to/test_test.go?Demo context", testcases[1].Attributes["matchedSelector"])
+}
+
+func TestLoadTestcasesWithSharedSpecInFiles(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(projPath, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	// 复用testdata的依赖，以便编译ginkgo测试套
+	for _, name := range []string{"go.mod", "go.sum"} {
+		content, err := os.ReadFile(filepath.Join("../../testdata", name))
+		assert.NoError(t, err)
+		writeFile(name, string(content))
+	}
+	modulePath := strings.TrimPrefix(strings.SplitN(readFile(t, filepath.Join(projPath, "go.mod")), "\n", 2)[0], "module ")
+	// 共享用例定义在包外，动态加载时用例路径会被改写为下发的文件
+	writeFile("behaviors/behaviors.go", "package behaviors\n\nimport . \"github.com/onsi/ginkgo/v2\"\n\nfunc DescribeWorks(text string) bool {\n\treturn Describe(text, func() {\n\t\tIt(\"works\", func() {})\n\t})\n}\n")
+	writeFile("app/app_suite_test.go", "package app\n\nimport (\n\t\"testing\"\n\n\t. \"github.com/onsi/ginkgo/v2\"\n)\n\nfunc TestApp(t *testing.T) {\n\tRunSpecs(t, \"app\")\n}\n")
+	for _, name := range []string{"a", "b"} {
+		writeFile("app/"+name+"_test.go", fmt.Sprintf("package app\n\nimport \"%s/behaviors\"\n\nvar _ = behaviors.DescribeWorks(\"%s\")\n", modulePath, strings.ToUpper(name)))
+	}
+	t.Setenv("TESTSOLAR_TTP_PARSEMODE", "dynamic")
+	t.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	testcases, loadErrors := LoadTestcases(projPath, ParseTestSelectors([]string{"app/a_test.go", "app/b_test.go"}))
+	assert.Len(t, loadErrors, 0)
+	selectors := map[string]bool{}
+	for _, testcase := range testcases {
+		selectors[testcase.GetSelector()] = true
+	}
+	// 两个文件的选择器各自改写共享用例的路径，互不影响
+	assert.True(t, selectors["app/a_test.go?A works"], selectors)
+	assert.True(t, selectors["app/b_test.go?B works"], selectors)
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(content)
+}
+
 func TestRunDiscover(t *testing.T) {
 	reportTestcasesMock := gomonkey.ApplyFunc(ReportTestcases, func(testcases []*ginkgoTestcase.TestCase, loadErrors []*sdkModel.LoadError, reporter api.Reporter) error {
 		return nil
@@ -3,15 +3,20 @@ package execute
 import (
 	"errors"
 	"fmt"
-	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	ginkgoBuilder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
+	cmdpkg "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/cmdline"
+	ginkgoCoverage "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/coverage"
+	ginkgoLoader "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/loader"
 	ginkgoRunner "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/runner"
+	ginkgoSelector "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/selector"
 	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
 	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
 
@@ -79,81 +84,73 @@ func reportTestResults(testResults []*sdkModel.TestResult, reporter api.Reporter
 	return nil
 }
 
+// findTestPackagesByPath 查找目录下调用了RunSpecs的测试套以及包含标准go测试的包所在目录
 func findTestPackagesByPath(path string) ([]string, error) {
+	suiteDirs, noEntryDirs, err := ginkgoUtil.FindTestPackages(path)
+	if err != nil {
+		return nil, pkgErrors.Wrapf(err, "walk dir %s failed", path)
+	}
+	for _, dir := range noEntryDirs {
+		log.Printf("[PLUGIN]skip %s, it has ginkgo specs but no RunSpecs entrypoint", dir)
+	}
+	// 调用方基于传入的路径拼接二进制文件路径，因此需要保持与传入路径相同的相对或者绝对形式
 	subDirs := []string{}
-	foundSubDirs := map[string]bool{}
-	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+	for _, dir := range suiteDirs {
+		if filepath.IsAbs(path) {
+			subDirs = append(subDirs, dir)
+			continue
+		}
+		absPath, err := filepath.Abs(path)
 		if err != nil {
-			return pkgErrors.Wrapf(err, "walk subdir %s failed", p)
+			return nil, pkgErrors.Wrapf(err, "get abs path of %s failed", path)
 		}
-		if !d.IsDir() && strings.HasSuffix(p, "_suite_test.go") {
-			subDir := filepath.Dir(p)
-			if _, ok := foundSubDirs[subDir]; !ok {
-				subDirs = append(subDirs, subDir)
-				foundSubDirs[subDir] = true
-			}
-			return nil
+		relPath, err := filepath.Rel(absPath, dir)
+		if err != nil {
+			return nil, pkgErrors.Wrapf(err, "get rel path of %s failed", dir)
 		}
-		return nil
-	})
-	if err != nil {
-		return nil, pkgErrors.Wrapf(err, "walk dir %s failed", path)
+		subDirs = append(subDirs, filepath.Join(path, relPath))
 	}
 	return subDirs, nil
 }
 
-func findTestBinaryInDir(dir string) (string, error) {
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		log.Printf("failed to read dir: %s, err: %v", dir, err)
-		return "", err
-	}
-	for _, file := range files {
-		if !file.IsDir() {
-			if strings.HasSuffix(file.Name(), ".test") {
-				log.Printf("[PLUGIN]find test binary file %s in dir %s", file.Name(), dir)
-				return filepath.Join(dir, file.Name()), nil
-			}
+// findManifestPackage 用例源码不存在时，在编译清单中查找用例所属的包
+// 用例路径相对于当前工作目录(即用例库根目录)，返回的包路径保持与用例路径相同的相对或者绝对形式
+func findManifestPackage(projPath string, manifest *ginkgoBuilder.Manifest, path string) (string, bool) {
+	relPath := path
+	if filepath.IsAbs(path) {
+		var err error
+		if relPath, err = filepath.Rel(projPath, path); err != nil {
+			return "", false
 		}
 	}
-	return "", nil
-}
-
-// findCompileBinary 通过用例路径查询对应二进制可执行文件
-// 通常情况下预编译生成的二进制文件与用例文件所在目录同级
-// 但是部分场景下二进制文件可能处于更上层目录，需要递归查询
-func findCompileBinary(path string) string {
-	for {
-		preCompileFile := path + ".test"
-		if _, err := os.Stat(preCompileFile); err != nil {
-			if binaryFile, err := findTestBinaryInDir(path); err == nil && binaryFile != "" {
-				return binaryFile
-			}
-			path = filepath.Dir(path)
-			if path == "." || path == "/" || path == "\\" {
-				break
-			} else {
-				continue
-			}
-		} else {
-			return preCompileFile
-		}
+	packagePath, ok := manifest.FindPackage(relPath)
+	if !ok {
+		return "", false
 	}
-	return ""
+	if filepath.IsAbs(path) {
+		return filepath.Join(projPath, packagePath), true
+	}
+	return filepath.FromSlash(packagePath), true
 }
 
-func discoverExecutableTestcases(testcases []*ginkgoTestcase.TestCase) ([]*ginkgoTestcase.TestCase, error) {
+// discoverExecutableTestcases 将目录形式的用例展开为目录下的测试包，用例源码不存在时根据编译清单找到预编译二进制文件所属的包
+func discoverExecutableTestcases(projPath string, testcases []*ginkgoTestcase.TestCase) ([]*ginkgoTestcase.TestCase, error) {
 	excutableTestcases := []*ginkgoTestcase.TestCase{}
+	manifest, err := ginkgoBuilder.ReadManifest(projPath)
+	if err != nil {
+		log.Printf("[PLUGIN]read manifest failed, err: %v", err)
+		manifest = &ginkgoBuilder.Manifest{}
+	}
 	for _, testcase := range testcases {
 		fd, err := os.Stat(testcase.Path)
 		if err != nil {
-			preCompileFile := findCompileBinary(filepath.Dir(testcase.Path))
-			if preCompileFile == "" {
-				log.Printf("[PLUGIN]get file info %s failed and there is no precompiled binary file, err: %s", testcase.Path, err.Error())
+			packagePath, ok := findManifestPackage(projPath, manifest, filepath.Dir(testcase.Path))
+			if !ok {
+				log.Printf("[PLUGIN]get file info %s failed and there is no precompiled binary file in manifest, err: %s", testcase.Path, err.Error())
 				continue
 			}
-			log.Printf("[PLUGIN]can't find testcase file %s, but find precompiled binary file %s", testcase.Path, preCompileFile)
-			testcase.Path = strings.TrimSuffix(preCompileFile, ".test")
+			log.Printf("[PLUGIN]can't find testcase file %s, but find precompiled package %s in manifest", testcase.Path, packagePath)
+			testcase.Path = packagePath
 			excutableTestcases = append(excutableTestcases, testcase)
 			continue
 		}
@@ -183,32 +180,182 @@ func discoverExecutableTestcases(testcases []*ginkgoTestcase.TestCase) ([]*ginkg
 	return excutableTestcases, nil
 }
 
+// excludeTestcases 过滤掉满足排除选择器的用例
+// 目录或者文件形式的用例(用例名为空)无法按用例名、行号或者属性匹配，与这类排除选择器路径重叠时先加载展开为具体用例再过滤
+func excludeTestcases(projPath string, testcases []*ginkgoTestcase.TestCase, excludeSelectors []*ginkgoSelector.TestSelector) []*ginkgoTestcase.TestCase {
+	var filtered []*ginkgoTestcase.TestCase
+	for _, testcase := range testcases {
+		if testcase.Name == "" && needExpand(testcase.Path, excludeSelectors) {
+			if loaded, ok := loadTestcasesInPath(projPath, testcase.Path); ok {
+				kept := ginkgoSelector.ExcludeTestCases(loaded, excludeSelectors)
+				filtered = append(filtered, removeExcludedParents(loaded, kept)...)
+				continue
+			}
+		}
+		filtered = append(filtered, ginkgoSelector.ExcludeTestCases([]*ginkgoTestcase.TestCase{testcase}, excludeSelectors)...)
+	}
+	return filtered
+}
+
+// needExpand 判断是否存在指定了用例名、行号或者属性且与路径重叠的排除选择器
+func needExpand(path string, excludeSelectors []*ginkgoSelector.TestSelector) bool {
+	for _, excludeSelector := range excludeSelectors {
+		if excludeSelector.FiltersTestCases() && excludeSelector.OverlapsPath(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTestcasesInPath 加载包或者文件中的用例，path为包目录时只保留该包内的用例，子目录中的包已经展开为单独的用例
+// 加载不到任何用例时返回false，由调用方按原用例执行
+func loadTestcasesInPath(projPath string, path string) ([]*ginkgoTestcase.TestCase, bool) {
+	relPath := path
+	if filepath.IsAbs(path) {
+		var err error
+		if relPath, err = filepath.Rel(projPath, path); err != nil {
+			log.Printf("[PLUGIN]get rel path of %s failed, err: %v", path, err)
+			return nil, false
+		}
+	}
+	loaded, loadErrors := ginkgoLoader.LoadTestCase(projPath, relPath)
+	for _, loadError := range loadErrors {
+		log.Printf("[PLUGIN]load testcases in %s to apply exclude selectors, load error %s: %s", relPath, loadError.Name, loadError.Message)
+	}
+	var testcases []*ginkgoTestcase.TestCase
+	for _, testcase := range loaded {
+		casePackage := testcase.Path
+		if strings.HasSuffix(casePackage, ".go") {
+			casePackage = filepath.Dir(casePackage)
+		}
+		if !strings.HasSuffix(relPath, ".go") && filepath.Clean(casePackage) != filepath.Clean(relPath) {
+			continue
+		}
+		if filepath.IsAbs(path) {
+			testcase.Path = filepath.Join(projPath, testcase.Path)
+		}
+		testcases = append(testcases, testcase)
+	}
+	if len(testcases) == 0 {
+		log.Printf("[PLUGIN]no testcases loaded in %s, exclude selectors with name or attributes are not applied to it", relPath)
+		return nil, false
+	}
+	log.Printf("[PLUGIN]expand %s to %d testcases to apply exclude selectors", relPath, len(testcases))
+	return testcases, true
+}
+
+// removeExcludedParents 标准go测试的子测试被排除时，执行其父测试仍会执行被排除的子测试，因此不再执行父测试，只执行未被排除的子测试
+func removeExcludedParents(loaded []*ginkgoTestcase.TestCase, kept []*ginkgoTestcase.TestCase) []*ginkgoTestcase.TestCase {
+	keptCases := map[*ginkgoTestcase.TestCase]bool{}
+	for _, testcase := range kept {
+		keptCases[testcase] = true
+	}
+	excludedParents := map[string]bool{}
+	for _, testcase := range loaded {
+		if keptCases[testcase] || testcase.Attributes["framework"] != ginkgoTestcase.FrameworkGoTest {
+			continue
+		}
+		levels := strings.Split(testcase.Name, "/")
+		for i := 1; i < len(levels); i++ {
+			excludedParents[strings.Join(levels[:i], "/")] = true
+		}
+	}
+	var testcases []*ginkgoTestcase.TestCase
+	for _, testcase := range kept {
+		if testcase.Attributes["framework"] == ginkgoTestcase.FrameworkGoTest && excludedParents[testcase.Name] {
+			log.Printf("[PLUGIN]skip %s, some of its subtests are excluded", testcase.GetSelector())
+			continue
+		}
+		testcases = append(testcases, testcase)
+	}
+	return testcases
+}
+
+// prepareTestBinary 返回包的二进制文件，优先使用编译清单中记录的二进制文件，二进制文件不存在时编译生成，源码或者依赖在编译后发生变化时重新编译
+func prepareTestBinary(projPath string, path string) (string, error) {
+	pkgBin := ginkgoBuilder.ResolveBinary(projPath, path)
+	if pkgBin == "" {
+		log.Printf("Can't find package bin file of %s during running, try to build it...", path)
+		return ginkgoBuilder.BuildTestPackage(projPath, path, false)
+	}
+	if ginkgoBuilder.IsBinaryStale(projPath, path) {
+		log.Printf("Package bin file %s is stale, try to rebuild it...", pkgBin)
+		rebuiltBin, err := ginkgoBuilder.BuildTestPackage(projPath, path, false)
+		if err != nil {
+			// 重新编译失败时原有的二进制文件仍然存在，继续使用原有的二进制文件执行
+			log.Printf("Rebuild package %s during running failed, use the stale bin file, err: %s", path, err.Error())
+			return pkgBin, nil
+		}
+		return rebuiltBin, nil
+	}
+	return pkgBin, nil
+}
+
 func executeTestcases(projPath string, packages map[string]map[string][]*ginkgoTestcase.TestCase) ([]*sdkModel.TestResult, error) {
 	var testResults []*sdkModel.TestResult
+	var coverages []*ginkgoCoverage.PackageCoverage
 	for path, filesCases := range packages {
+		pkgBin, err := prepareTestBinary(projPath, path)
+		if err != nil {
+			log.Printf("Build package %s during running failed, err: %s", path, err.Error())
+			continue
+		}
+		packageStart := len(testResults)
+		coverDir := preparePackageCoverage(projPath, path)
+		restoreCoverDir := setGoCoverDir(coverDir)
+		packageDir := filepath.Join(projPath, path)
+		goTests, err := ginkgoUtil.FindGoTests(packageDir)
+		if err != nil {
+			log.Printf("Find go tests in %s failed, err: %s", path, err.Error())
+		}
+		suites, err := ginkgoUtil.FindTestifySuites(packageDir)
+		if err != nil {
+			log.Printf("Find testify suites in %s failed, err: %s", path, err.Error())
+		}
+		// 只包含标准go测试或者testify测试套的包没有ginkgo测试套入口
+		hasGinkgoSuite := true
+		if len(goTests) > 0 || len(suites) > 0 {
+			if _, err := ginkgoUtil.FindSuiteFile(packageDir); err != nil {
+				hasGinkgoSuite = false
+			}
+		}
 		// test one suite each time
 		for filename, cases := range filesCases {
-			pkgBin := filepath.Join(projPath, path+".test")
-			_, err := os.Stat(pkgBin)
-			if err != nil {
-				log.Printf("Can't find package bin file %s during running, try to build it...", pkgBin)
-				_, err := ginkgoBuilder.BuildTestPackage(projPath, path, false)
+			split := splitPackageCases(goTests, suites, filename, cases)
+			ginkgoCases := split.ginkgo
+			if !hasGinkgoSuite {
+				ginkgoCases = nil
+			}
+			if len(split.goTests) > 0 {
+				log.Printf("Run go tests: %v in file %s by bin file %s", split.goTests, filename, pkgBin)
+				results, err := ginkgoRunner.RunGoTest(projPath, pkgBin, path, goTests, split.goTests)
 				if err != nil {
-					log.Printf("Build package %s during running failed, err: %s", path, err.Error())
-					continue
+					log.Printf("Run go tests failed, err: %s", err.Error())
+				}
+				testResults = append(testResults, results...)
+			}
+			if len(split.testify) > 0 {
+				log.Printf("Run testify tests: %v in file %s by bin file %s", split.testify, filename, pkgBin)
+				results, err := ginkgoRunner.RunTestifyTest(projPath, pkgBin, path, suites, split.testify)
+				if err != nil {
+					log.Printf("Run testify tests failed, err: %s", err.Error())
 				}
+				testResults = append(testResults, results...)
 			}
-			tcNames := make([]string, len(cases))
-			for i, tc := range cases {
+			if len(ginkgoCases) == 0 {
+				continue
+			}
+			tcNames := make([]string, len(ginkgoCases))
+			for i, tc := range ginkgoCases {
 				tcNames[i] = tc.Name
 			}
-			ginkgoVersion := ginkgoUtil.FindGinkgoVersion(strings.TrimSuffix(pkgBin, ".test"))
+			ginkgoVersion := ginkgoBuilder.PackageGinkgoVersion(projPath, path)
 			log.Printf("Run test cases: %v in file %s by bin file %s", tcNames, filename, pkgBin)
 			var results []*sdkModel.TestResult
 			if ginkgoVersion == 1 {
-				results, err = ginkgoRunner.RunGinkgoV1Test(projPath, pkgBin, filepath.Join(path, filename), tcNames)
+				results, err = ginkgoRunner.RunGinkgoV1Test(projPath, pkgBin, path, filepath.Join(path, filename), tcNames)
 			} else {
-				results, err = ginkgoRunner.RunGinkgoV2Test(projPath, pkgBin, filepath.Join(path, filename), tcNames)
+				results, err = ginkgoRunner.RunGinkgoV2Test(projPath, pkgBin, path, filepath.Join(path, filename), tcNames)
 			}
 			if err != nil {
 				log.Printf("Run test cases failed, err: %s", err.Error())
@@ -219,14 +366,212 @@ func executeTestcases(projPath string, packages map[string]map[string][]*ginkgoT
 			}
 			testResults = append(testResults, results...)
 		}
+		restoreCoverDir()
+		setBuildFlagsAttribute(testResults[packageStart:], ginkgoBuilder.BinaryBuildFlags(projPath, path))
+		if coverDir == "" {
+			continue
+		}
+		pkgCoverage, err := ginkgoCoverage.ReportPackage(projPath, path)
+		if err != nil {
+			log.Printf("Report coverage of package %s failed, err: %s", path, err.Error())
+			continue
+		}
+		log.Printf("Coverage of package %s: %s%%", path, ginkgoCoverage.FormatPercent(pkgCoverage.Percent))
+		setCoverageAttributes(testResults[packageStart:], pkgCoverage)
+		coverages = append(coverages, pkgCoverage)
+	}
+	if len(coverages) > 0 {
+		summary, err := ginkgoCoverage.Merge(projPath, coverages)
+		if err != nil {
+			log.Printf("Merge coverage failed, err: %s", err.Error())
+		} else {
+			summary.Log()
+		}
 	}
 	return testResults, nil
 }
 
+// preparePackageCoverage 开启覆盖率统计且二进制文件编译时开启了覆盖率统计时，返回包的覆盖率数据目录，否则返回空字符串
+func preparePackageCoverage(projPath string, path string) string {
+	if !ginkgoCoverage.Enabled() {
+		return ""
+	}
+	if !ginkgoBuilder.IsCoverBinary(projPath, path) {
+		log.Printf("Bin file of package %s is not built with coverage enabled, skip collecting coverage", path)
+		return ""
+	}
+	coverDir, err := ginkgoCoverage.PreparePackage(projPath, path)
+	if err != nil {
+		log.Printf("Prepare coverage dir of package %s failed, err: %s", path, err.Error())
+		return ""
+	}
+	return coverDir
+}
+
+// setGoCoverDir 设置执行包内用例时的GOCOVERDIR，coverDir为空时清除该环境变量，避免未开启覆盖率统计的二进制文件使用外部设置的GOCOVERDIR
+// 包内的用例按顺序执行，返回的函数用于恢复原有的环境变量
+func setGoCoverDir(coverDir string) func() {
+	origin, exists := os.LookupEnv("GOCOVERDIR")
+	if coverDir == "" {
+		_ = os.Unsetenv("GOCOVERDIR")
+	} else {
+		_ = os.Setenv("GOCOVERDIR", coverDir)
+	}
+	return func() {
+		if exists {
+			_ = os.Setenv("GOCOVERDIR", origin)
+		} else {
+			_ = os.Unsetenv("GOCOVERDIR")
+		}
+	}
+}
+
+// setCoverageAttributes 在用例结果中记录包的覆盖率，并以附件的形式附加包的覆盖率文件
+func setCoverageAttributes(results []*sdkModel.TestResult, pkgCoverage *ginkgoCoverage.PackageCoverage) {
+	for _, result := range results {
+		if result.Test == nil {
+			continue
+		}
+		// 同一批用例的结果可能共用属性，复制后再修改
+		attributes := map[string]string{}
+		for k, v := range result.Test.Attributes {
+			attributes[k] = v
+		}
+		attributes["coverage"] = ginkgoCoverage.FormatPercent(pkgCoverage.Percent)
+		attributes["coverageStatements"] = strconv.Itoa(pkgCoverage.Statements)
+		attributes["coverageCovered"] = strconv.Itoa(pkgCoverage.Covered)
+		result.Test.Attributes = attributes
+		result.Steps = append(result.Steps, &sdkModel.TestCaseStep{
+			StartTime:  result.EndTime,
+			EndTime:    result.EndTime,
+			Title:      "Coverage",
+			ResultType: sdkModel.ResultTypeSucceed,
+			Logs: []*sdkModel.TestCaseLog{
+				{
+					Time:    result.EndTime,
+					Level:   sdkModel.LogLevelInfo,
+					Content: fmt.Sprintf("coverage: %s%% of statements (%d/%d)", ginkgoCoverage.FormatPercent(pkgCoverage.Percent), pkgCoverage.Covered, pkgCoverage.Statements),
+					Attachments: []*sdkModel.Attachment{
+						{
+							Name:           ginkgoCoverage.ProfileFileName,
+							Url:            pkgCoverage.Profile,
+							AttachmentType: sdkModel.AttachmentTypeFile,
+						},
+					},
+				},
+			},
+		})
+	}
+}
+
+// setBuildFlagsAttribute 在用例结果中记录二进制文件编译时使用的编译参数，未指定编译参数时不记录
+func setBuildFlagsAttribute(results []*sdkModel.TestResult, buildFlags []string) {
+	if len(buildFlags) == 0 {
+		return
+	}
+	quoted := make([]string, len(buildFlags))
+	for i, flag := range buildFlags {
+		quoted[i] = flag
+		if strings.ContainsAny(flag, " \t'\"") {
+			quoted[i] = cmdpkg.ShellQuote(flag)
+		}
+	}
+	for _, result := range results {
+		if result.Test == nil {
+			continue
+		}
+		if result.Test.Attributes == nil {
+			result.Test.Attributes = map[string]string{}
+		}
+		result.Test.Attributes["buildFlags"] = strings.Join(quoted, " ")
+	}
+}
+
+// packageCases 包内按测试框架拆分后的用例
+type packageCases struct {
+	goTests []string
+	testify []string
+	ginkgo  []*ginkgoTestcase.TestCase
+}
+
+// nameSet 保持添加顺序的去重用例名列表
+type nameSet struct {
+	names    []string
+	selected map[string]bool
+}
+
+func (n *nameSet) add(name string) {
+	if n.selected == nil {
+		n.selected = map[string]bool{}
+	}
+	if !n.selected[name] {
+		n.selected[name] = true
+		n.names = append(n.names, name)
+	}
+}
+
+// splitPackageCases 将用例分为标准go测试、testify测试方法与ginkgo用例，goTests为包内顶层测试函数名到所在文件名的映射
+// 用例名的第一层(以`/`分隔)为包内测试函数时视为标准go测试，为testify测试套类型名时视为testify测试方法
+// 用例名为空表示执行整个文件或者包，此时同时执行其中的标准go测试、testify测试方法与ginkgo用例
+func splitPackageCases(goTests map[string]string, suites []*ginkgoUtil.TestifySuite, filename string, cases []*ginkgoTestcase.TestCase) *packageCases {
+	var goTestNames, testifyNames nameSet
+	var ginkgoCases []*ginkgoTestcase.TestCase
+	suiteNames := map[string]bool{}
+	for _, suite := range suites {
+		suiteNames[suite.Name] = true
+	}
+	for _, tc := range cases {
+		if tc.Name == "" {
+			var names []string
+			for name, file := range goTests {
+				if filename == "" || file == filename {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				goTestNames.add(name)
+			}
+			for _, suite := range suites {
+				if filename == "" {
+					testifyNames.add(suite.Name)
+					continue
+				}
+				for _, method := range suite.Methods {
+					if method.File == filename {
+						testifyNames.add(suite.Name + "/" + method.Name)
+					}
+				}
+			}
+			ginkgoCases = append(ginkgoCases, tc)
+			continue
+		}
+		top := strings.SplitN(tc.Name, "/", 2)[0]
+		if _, ok := goTests[top]; ok {
+			goTestNames.add(tc.Name)
+			continue
+		}
+		if suiteNames[top] {
+			testifyNames.add(tc.Name)
+			continue
+		}
+		ginkgoCases = append(ginkgoCases, tc)
+	}
+	return &packageCases{
+		goTests: goTestNames.names,
+		testify: testifyNames.names,
+		ginkgo:  ginkgoCases,
+	}
+}
+
 func parseTestcases(testSelectors []string) ([]*ginkgoTestcase.TestCase, []*sdkModel.TestResult, error) {
 	var testcases []*ginkgoTestcase.TestCase
 	var failedResults []*sdkModel.TestResult
 	for _, selector := range testSelectors {
+		if testSelector, err := ginkgoSelector.NewTestSelector(selector); err == nil && testSelector.IsExclude() {
+			// 排除选择器不对应需要执行的用例，在确定可执行用例后统一过滤
+			continue
+		}
 		testcase, err := ginkgoTestcase.ParseTestCaseBySelector(selector)
 		if err != nil {
 			message := fmt.Sprintf("parse testcase [%s] failed, err: %s", selector, err.Error())
@@ -259,16 +604,22 @@ func (o *ExecuteOptions) RunExecute(cmd *cobra.Command) error {
 	if err != nil {
 		return pkgErrors.Wrapf(err, "failed to parse test selectors")
 	}
-	// 递归查询包含实际可执行用例的目录
-	excutableTestcases, err := discoverExecutableTestcases(testcases)
-	if err != nil {
-		return pkgErrors.Wrapf(err, "failed to discover excutable testcases")
-	}
 	projPath := ginkgoUtil.GetWorkspace(config.ProjectPath)
 	_, err = os.Stat(projPath)
 	if err != nil {
 		return pkgErrors.Wrapf(err, "stat project path %s failed", projPath)
 	}
+	if ginkgoCoverage.Enabled() {
+		if err := ginkgoCoverage.Reset(projPath); err != nil {
+			log.Printf("Reset coverage data failed, err: %s", err.Error())
+		}
+	}
+	// 递归查询包含实际可执行用例的目录
+	excutableTestcases, err := discoverExecutableTestcases(projPath, testcases)
+	if err != nil {
+		return pkgErrors.Wrapf(err, "failed to discover excutable testcases")
+	}
+	excutableTestcases = excludeTestcases(projPath, excutableTestcases, ginkgoSelector.ParseExcludeSelectors(config.TestSelectors))
 	packages, err := groupTestCasesByPathAndName(projPath, excutableTestcases)
 	if err != nil {
 		return pkgErrors.Wrap(err, "failed to group testcases by path and name")
@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	ginkgoBuilder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
 	"github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
 
 	sdkApi "github.com/OpenTestSolar/testtool-sdk-golang/api"
 	sdkClient "github.com/OpenTestSolar/testtool-sdk-golang/client"
@@ -31,6 +33,11 @@ func TestParseTestcases(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, testcases, 1)
 	assert.Len(t, parseFailedResults, 1)
+
+	testcases, _, err = parseTestcases([]string{"demo", "demo/v1?exclude=true"})
+	assert.NoError(t, err)
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "demo", testcases[0].Path)
 }
 
 type MockReporterClient struct{}
@@ -134,6 +141,8 @@ func TestExecuteTestcases(t *testing.T) {
 			},
 		},
 	}
+	// 用例名`[demo test3]`与标签的格式相同，需要声明用例名中不包含标签，避免被当作标签去除
+	t.Setenv("TESTSOLAR_TTP_WITHOUTLABELS", "true")
 	results, err := executeTestcases(projPath, packages)
 	assert.NoError(t, err)
 	assert.Len(t, results, 3)
@@ -154,7 +163,7 @@ func Test_discoverExecutableTestcases(t *testing.T) {
 			Name: "",
 		},
 	}
-	execTestcases, err := discoverExecutableTestcases(testcases)
+	execTestcases, err := discoverExecutableTestcases(projPath, testcases)
 	assert.NoError(t, err)
 	assert.Len(t, execTestcases, 4)
 	// 验证如果传入的是文件路径则直接返回
@@ -168,7 +177,7 @@ func Test_discoverExecutableTestcases(t *testing.T) {
 			Name: "",
 		},
 	}
-	execTestcases, err = discoverExecutableTestcases(testcases)
+	execTestcases, err = discoverExecutableTestcases(projPath, testcases)
 	assert.NoError(t, err)
 	assert.Len(t, execTestcases, 2)
 	// 验证如果传入的已经是子目录则不会返回额外用例
@@ -178,45 +187,328 @@ func Test_discoverExecutableTestcases(t *testing.T) {
 			Name: "",
 		},
 	}
-	execTestcases, err = discoverExecutableTestcases(testcases)
+	execTestcases, err = discoverExecutableTestcases(projPath, testcases)
+	assert.NoError(t, err)
+	assert.Len(t, execTestcases, 1)
+}
+
+func Test_discoverPrecompiledPackage(t *testing.T) {
+	projPath := t.TempDir()
+	t.Setenv("TESTSOLAR_TTP_BINARYDIR", "out")
+	assert.NoError(t, os.WriteFile(filepath.Join(projPath, "go.mod"), []byte("module example.com/precompiled\n\ngo 1.19\n"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(projPath, "app"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(projPath, "app", "app_test.go"), []byte("package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n"), 0644))
+	pkgBin, err := ginkgoBuilder.BuildTestPackage(projPath, "app", false)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(projPath, "out", "app.test"), pkgBin)
+
+	manifest, err := ginkgoBuilder.ReadManifest(projPath)
+	assert.NoError(t, err)
+	packagePath, ok := findManifestPackage(projPath, manifest, filepath.Join("app", "sub"))
+	assert.True(t, ok)
+	assert.Equal(t, "app", packagePath)
+	packagePath, ok = findManifestPackage(projPath, manifest, filepath.Join(projPath, "app"))
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(projPath, "app"), packagePath)
+	_, ok = findManifestPackage(projPath, manifest, "other")
+	assert.False(t, ok)
+
+	// 只提供二进制文件而没有源码时，根据编译清单找到用例所属的包
+	assert.NoError(t, os.RemoveAll(filepath.Join(projPath, "app")))
+	curWd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(projPath))
+	defer os.Chdir(curWd) //nolint:all
+	execTestcases, err := discoverExecutableTestcases(projPath, []*testcase.TestCase{
+		{Path: "app/app_test.go", Name: "TestApp"},
+		{Path: "other/other_test.go", Name: "TestOther"},
+	})
 	assert.NoError(t, err)
 	assert.Len(t, execTestcases, 1)
+	assert.Equal(t, "app", execTestcases[0].Path)
+	pkgBin, err = prepareTestBinary(projPath, "app")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(projPath, "out", "app.test"), pkgBin)
+}
+
+func Test_splitPackageCases(t *testing.T) {
+	goTests := map[string]string{
+		"TestAdd":     "add_test.go",
+		"TestSkipped": "skip_test.go",
+	}
+	cases := []*testcase.TestCase{
+		{Name: "TestAdd/zero"},
+		{Name: "Testcase cont demo test"},
+		{Name: "TestAdd/zero"},
+	}
+	suites := []*ginkgoUtil.TestifySuite{
+		{
+			Name:      "UserSuite",
+			Entry:     "TestUserSuite",
+			EntryFile: "suite_test.go",
+			Methods: []*ginkgoUtil.TestifyMethod{
+				{Name: "TestCreate", File: "user_test.go"},
+				{Name: "TestDelete", File: "skip_test.go"},
+			},
+		},
+	}
+	cases = append(cases, &testcase.TestCase{Name: "UserSuite/TestCreate"})
+	split := splitPackageCases(goTests, suites, "", cases)
+	assert.Equal(t, []string{"TestAdd/zero"}, split.goTests)
+	assert.Equal(t, []string{"UserSuite/TestCreate"}, split.testify)
+	assert.Len(t, split.ginkgo, 1)
+	assert.Equal(t, "Testcase cont demo test", split.ginkgo[0].Name)
+	// 用例名为空时执行文件中的所有标准go测试与testify测试方法，同时保留ginkgo用例
+	split = splitPackageCases(goTests, suites, "skip_test.go", []*testcase.TestCase{{Name: ""}})
+	assert.Equal(t, []string{"TestSkipped"}, split.goTests)
+	assert.Equal(t, []string{"UserSuite/TestDelete"}, split.testify)
+	assert.Len(t, split.ginkgo, 1)
+	split = splitPackageCases(goTests, suites, "", []*testcase.TestCase{{Name: ""}})
+	assert.Equal(t, []string{"TestAdd", "TestSkipped"}, split.goTests)
+	assert.Equal(t, []string{"UserSuite"}, split.testify)
 }
 
-func Test_findCompileBinary(t *testing.T) {
-	result := findCompileBinary("path")
-	assert.Equal(t, "", result)
-	result = findCompileBinary("/path/to/file")
-	assert.Equal(t, "", result)
-	result = findCompileBinary("path/to/file")
-	assert.Equal(t, "", result)
-	result = findCompileBinary("path\\to\\file")
-	assert.Equal(t, "", result)
-	result = findCompileBinary("")
-	assert.Equal(t, "", result)
-	result = findCompileBinary("/")
-	assert.Equal(t, "", result)
-	result = findCompileBinary("//")
-	assert.Equal(t, "", result)
-	result = findCompileBinary("\\")
-	assert.Equal(t, "", result)
-	// 测试存在二进制文件场景
+func TestExecuteGoTestcases(t *testing.T) {
 	projPath, err := filepath.Abs("../../testdata")
 	assert.NoError(t, err)
-	binFile := filepath.Join(projPath, "demo01.test")
-	_, err = os.Create(binFile)
+	defer os.Remove(filepath.Join(projPath, "gotest.test"))
+	packages := map[string]map[string][]*testcase.TestCase{
+		"gotest": {
+			"gotest_test.go": {
+				{
+					Path: "gotest/gotest_test.go",
+					Name: "",
+				},
+			},
+		},
+	}
+	results, err := executeTestcases(projPath, packages)
 	assert.NoError(t, err)
-	result = findCompileBinary(filepath.Join(projPath, "demo01"))
-	assert.Equal(t, binFile, result)
-	result = findCompileBinary(filepath.Join(projPath, "demo01", "test"))
-	assert.Equal(t, binFile, result)
-	// 测试存在不与目录同名的二进制文件时
-	err = os.Remove(binFile)
+	assert.Len(t, results, 5)
+	for _, result := range results {
+		assert.Equal(t, "gotest", result.Test.Attributes["framework"])
+	}
+}
+
+func TestExecuteTestifyTestcases(t *testing.T) {
+	projPath, err := filepath.Abs("../../testdata")
 	assert.NoError(t, err)
-	otherBinFile := filepath.Join(projPath, "demo01_xxx.test")
-	_, err = os.Create(otherBinFile)
+	defer os.Remove(filepath.Join(projPath, "testify.test"))
+	packages := map[string]map[string][]*testcase.TestCase{
+		"testify": {
+			"user_test.go": {
+				{
+					Path: "testify/user_test.go",
+					Name: "UserSuite/TestCreate",
+				},
+				{
+					Path: "testify/user_test.go",
+					Name: "UserSuite/TestDelete",
+				},
+			},
+		},
+	}
+	results, err := executeTestcases(projPath, packages)
 	assert.NoError(t, err)
-	result = findCompileBinary(filepath.Join(projPath, "demo01"))
-	assert.NotEqual(t, binFile, result)
-	defer os.Remove(binFile)
+	assert.Len(t, results, 2)
+	for _, result := range results {
+		assert.Equal(t, "testify", result.Test.Attributes["framework"])
+		assert.Equal(t, sdkModel.ResultTypeSucceed, result.ResultType)
+	}
+}
+
+func TestExecuteRebuildsStaleBinary(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(projPath, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile("go.mod", "module example.com/stale\n\ngo 1.19\n")
+	writeFile("app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n")
+	packages := map[string]map[string][]*testcase.TestCase{
+		"app": {
+			"app_test.go": {
+				{
+					Path: "app/app_test.go",
+					Name: "TestApp",
+				},
+			},
+		},
+	}
+	results, err := executeTestcases(projPath, packages)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results[0].ResultType)
+
+	// 源码变化后重新编译，执行的是最新的代码
+	writeFile("app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) { t.Fatal(\"changed\") }\n")
+	results, err = executeTestcases(projPath, packages)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[0].ResultType)
+}
+
+func TestExecuteWithBuildFlags(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(projPath, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile("go.mod", "module example.com/flags\n\ngo 1.19\n")
+	writeFile("app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n")
+	writeFile("app/integration_test.go", "//go:build integration\n\npackage app\n\nimport \"testing\"\n\nfunc TestIntegration(t *testing.T) {}\n")
+	writeFile("build.json", `{"packages": {"app": "-tags integration -gcflags 'all=-N -l'"}}`)
+	t.Setenv("TESTSOLAR_TTP_BUILDCONFIG", "build.json")
+	packages := map[string]map[string][]*testcase.TestCase{
+		"app": {
+			"integration_test.go": {
+				{
+					Path: "app/integration_test.go",
+					Name: "TestIntegration",
+				},
+			},
+		},
+	}
+	results, err := executeTestcases(projPath, packages)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results[0].ResultType)
+	assert.Equal(t, "-tags integration -gcflags 'all=-N -l'", results[0].Test.Attributes["buildFlags"])
+}
+
+func TestRunExecuteWithExcludedNames(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(projPath, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile("go.mod", "module example.com/exclude\n\ngo 1.19\n")
+	writeFile("app/a_test.go", `package app
+
+import "testing"
+
+func TestA(t *testing.T) {}
+
+func TestAdd(t *testing.T) {
+	t.Run("one", func(t *testing.T) {})
+	t.Run("zero", func(t *testing.T) { t.Fatal("excluded") })
+}
+`)
+	writeFile("app/b_test.go", "package app\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) { t.Fatal(\"excluded\") }\n\nfunc TestC(t *testing.T) {}\n")
+	t.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	// 用例路径相对于用例库根目录，执行时以用例库根目录作为工作目录
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(projPath))
+	defer func() {
+		assert.NoError(t, os.Chdir(wd))
+	}()
+	unmarshalCaseInfoMock := gomonkey.ApplyFunc(testcase.UnmarshalCaseInfo, func(path string) (*sdkModel.EntryParam, error) {
+		return &sdkModel.EntryParam{
+			TestSelectors: []string{
+				"app",
+				"app/b_test.go?name=TestB&exclude=true",
+				"app/a_test.go?name=TestAdd/zero&exclude=true",
+			},
+			ProjectPath:    projPath,
+			FileReportPath: projPath,
+		}, nil
+	})
+	defer unmarshalCaseInfoMock.Reset()
+	var reported []*sdkModel.TestResult
+	reportTestResultsMock := gomonkey.ApplyFunc(reportTestResults, func(testResults []*sdkModel.TestResult, reporter sdkApi.Reporter) error {
+		reported = testResults
+		return nil
+	})
+	defer reportTestResultsMock.Reset()
+	o := NewExecuteOptions()
+	err = o.RunExecute(NewCmdExecute())
+	assert.NoError(t, err)
+	results := map[string]sdkModel.ResultType{}
+	for _, result := range reported {
+		results[result.Test.Name] = result.ResultType
+	}
+	assert.NotContains(t, results, "app/b_test.go?TestB")
+	assert.NotContains(t, results, "app/a_test.go?TestAdd/zero")
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results["app/a_test.go?TestA"])
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results["app/a_test.go?TestAdd/one"])
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results["app/b_test.go?TestC"])
+}
+
+func TestExecuteWithCoverage(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile := func(name, content string) {
+		path := filepath.Join(projPath, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	// 复用testdata的依赖，以便编译ginkgo测试套
+	for _, name := range []string{"go.mod", "go.sum"} {
+		content, err := os.ReadFile(filepath.Join("../../testdata", name))
+		assert.NoError(t, err)
+		writeFile(name, string(content))
+	}
+	writeFile("calc/calc.go", "package calc\n\nfunc Add(a, b int) int {\n\tif a > 0 {\n\t\treturn a + b\n\t}\n\treturn b\n}\n")
+	writeFile("calc/calc_test.go", "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) { Add(1, 2) }\n")
+	writeFile("suite/suite.go", "package suite\n\nfunc Double(a int) int {\n\treturn a * 2\n}\n\nfunc Half(a int) int {\n\treturn a / 2\n}\n")
+	writeFile("suite/suite_test.go", `package suite
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "suite")
+}
+
+var _ = Describe("suite", func() {
+	It("doubles", func() {
+		Expect(Double(2)).To(Equal(4))
+	})
+})
+`)
+	t.Setenv("TESTSOLAR_TTP_COVERAGE", "true")
+	t.Setenv("GOCOVERDIR", "")
+	packages := map[string]map[string][]*testcase.TestCase{
+		"calc": {
+			"calc_test.go": {
+				{
+					Path: "calc/calc_test.go",
+					Name: "TestAdd",
+				},
+			},
+		},
+		"suite": {
+			"suite_test.go": {
+				{
+					Path: "suite/suite_test.go",
+					Name: "suite doubles",
+				},
+			},
+		},
+	}
+	results, err := executeTestcases(projPath, packages)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	coverages := map[string]string{}
+	for _, result := range results {
+		assert.Equal(t, sdkModel.ResultTypeSucceed, result.ResultType, result.Test.Name)
+		assert.Equal(t, "-cover", result.Test.Attributes["buildFlags"])
+		coverages[result.Test.Name] = result.Test.Attributes["coverage"]
+		step := result.Steps[len(result.Steps)-1]
+		assert.Equal(t, "Coverage", step.Title)
+		assert.Len(t, step.Logs[0].Attachments, 1)
+		assert.FileExists(t, step.Logs[0].Attachments[0].Url)
+	}
+	assert.Equal(t, map[string]string{"calc/calc_test.go?TestAdd": "66.7", "suite/suite_test.go?suite doubles": "50.0"}, coverages)
+	assert.FileExists(t, filepath.Join(projPath, ".testtool", "coverage", "coverage.out"))
+	assert.FileExists(t, filepath.Join(projPath, ".testtool", "coverage", "summary.json"))
+	assert.Equal(t, "", os.Getenv("GOCOVERDIR"))
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"github.com/OpenTestSolar/testtool-golang-ginkgo/cmd/build"
 	"github.com/OpenTestSolar/testtool-golang-ginkgo/cmd/discover"
 	"github.com/OpenTestSolar/testtool-golang-ginkgo/cmd/execute"
@@ -15,5 +17,8 @@ func main() {
 	rootCmd.AddCommand(discover.NewCmdDiscover())
 	rootCmd.AddCommand(execute.NewCmdExecute())
 	rootCmd.AddCommand(build.NewCmdBuild())
-	_ = rootCmd.Execute()
+	// 命令执行失败时以非零状态码退出，例如编译时存在编译失败的包
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
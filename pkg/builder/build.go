@@ -1,16 +1,18 @@
 package builder
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
 
+	cmdpkg "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/cmdline"
 	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
 
 	"github.com/avast/retry-go"
@@ -18,53 +20,76 @@ import (
 )
 
 const (
-	MaxBuildConcurrency  = 2
 	MaxExecCmdRetry      = 3
 	ExecCmdRetryInterval = 2 * time.Second
 )
 
 func Build(projPath string) error {
 	var packageList []string
-	err := filepath.Walk(projPath, func(path string, fi os.FileInfo, _ error) error {
-		if strings.HasSuffix(path, "_suite_test.go") && !strings.Contains(path, ".testtool") {
-			packagePath := filepath.Dir(path)
-			if packagePath == projPath {
-				packagePath = ""
-			} else {
-				packagePath = packagePath[len(projPath)+1:]
-			}
-			if !ginkgoUtil.ElementIsInSlice(packagePath, packageList) {
-				packageList = append(packageList, packagePath)
-			}
-		}
-		return nil
-	})
+	// 只包含标准go测试的包同样需要编译
+	testDirs, noEntryDirs, err := ginkgoUtil.FindTestPackages(projPath)
 	if err != nil {
 		return err
 	}
-
-	concBuild, _ := strconv.ParseBool(os.Getenv("TESTSOlAR_TTP_CONCURRENTBUILD"))
-	concurrencyLevel := 1
-	if concBuild {
-		log.Printf("enable concurrent building package")
-		if runtime.GOMAXPROCS(0)+2 > MaxBuildConcurrency {
-			concurrencyLevel = MaxBuildConcurrency
-		} else {
-			concurrencyLevel = runtime.GOMAXPROCS(0) + 2
+	for _, dir := range noEntryDirs {
+		log.Printf("Skip building %s, it has ginkgo specs but no RunSpecs entrypoint", dir)
+	}
+	for _, dir := range testDirs {
+		packagePath, err := filepath.Rel(projPath, dir)
+		if err != nil {
+			return err
 		}
-		log.Printf("Build package concurrency level %d", concurrencyLevel)
+		if packagePath == "." {
+			packagePath = ""
+		}
+		packageList = append(packageList, packagePath)
 	}
-	p := pool.New().WithMaxGoroutines(concurrencyLevel).WithErrors().WithFirstError()
+
+	concurrencyLevel := BuildConcurrency()
+	log.Printf("Build package concurrency level %d", concurrencyLevel)
+	limiter := newBuildLimiter(concurrencyLevel)
 	compress, _ := strconv.ParseBool(os.Getenv("TESTSOLAR_TTP_COMPRESSBINARY"))
 	log.Printf("compress binaries %v", compress)
-	for _, packagePath := range packageList {
+	// 所有包都会被编译，默认只返回第一个编译失败的错误，开启continueOnError时汇总所有编译失败的包
+	continueOnError, _ := strconv.ParseBool(os.Getenv("TESTSOLAR_TTP_CONTINUEONERROR"))
+	p := pool.New().WithMaxGoroutines(concurrencyLevel).WithErrors()
+	if !continueOnError {
+		p = p.WithFirstError()
+	}
+	report := &BuildReport{
+		StartTime: time.Now(),
+		Total:     len(packageList),
+		Packages:  make([]*PackageBuildResult, len(packageList)),
+	}
+	for i, packagePath := range packageList {
+		i, packagePath := i, packagePath
 		log.Printf("Build package %s", packagePath)
 		p.Go(func() error {
-			return buildAndCompressTestBin(projPath, packagePath, compress)
+			startTime := time.Now()
+			upToDate, err := buildAndCompressTestBin(projPath, packagePath, compress, limiter)
+			report.Packages[i] = newPackageBuildResult(projPath, packagePath, upToDate, time.Since(startTime), err)
+			log.Printf("Build package %s %s, cost %.2fs", packagePath, report.Packages[i].Status, report.Packages[i].Duration)
+			return err
 		})
 	}
 	err = p.Wait()
+	report.Packages = report.finishedPackages()
+	report.Duration = time.Since(report.StartTime).Seconds()
+	report.Failed = len(report.FailedPackages())
+	report.Concurrency = concurrencyLevel
+	report.FinalConcurrency = limiter.current()
+	report.logTimings()
+	reportFile := BuildReportFile(projPath)
+	if saveErr := report.Save(reportFile); saveErr != nil {
+		log.Printf("Save build report failed, err: %v", saveErr)
+	} else {
+		log.Printf("Build report is written to %s", reportFile)
+	}
+	_ = report.WriteTable(os.Stdout)
 	if err != nil {
+		if continueOnError {
+			return fmt.Errorf("build %d of %d packages failed: %s", report.Failed, report.Total, strings.Join(report.FailedPackages(), ", "))
+		}
 		return fmt.Errorf("build package failed, err: %s", err.Error())
 	}
 	return nil
@@ -94,12 +119,24 @@ func compressBinFile(projPath, pkgBin string) error {
 	return nil
 }
 
-func buildAndCompressTestBin(projPath string, packagePath string, compress bool) error {
+// buildAndCompressTestBin 编译并压缩包的二进制文件，二进制文件已是最新时跳过编译并返回true
+func buildAndCompressTestBin(projPath string, packagePath string, compress bool, limiter *buildLimiter) (bool, error) {
+	pkgBin := BinaryPath(projPath, packagePath)
+	buildFlags, err := compileFlags(projPath, packagePath, compress)
+	if err != nil {
+		log.Printf("Get build flags of package %s failed, err: %s", packagePath, err.Error())
+		return false, err
+	}
+	fingerprint := packageFingerprint(projPath, packagePath, buildFlags)
+	if binaryUpToDate(projPath, packagePath, pkgBin, buildFlags, fingerprint) {
+		log.Printf("Skip building package %s, bin file %s is up to date", packagePath, pkgBin)
+		return true, nil
+	}
 	startTime := time.Now()
-	pkgBin, err := BuildTestPackage(projPath, packagePath, compress)
+	pkgBin, err = compileTestPackage(context.Background(), projPath, packagePath, compress, buildFlags, fingerprint, limiter)
 	if err != nil {
 		log.Printf("Build package %s failed, err: %s", packagePath, err.Error())
-		return err
+		return false, err
 	}
 	endTime := time.Now()
 	log.Printf("Run compile command cost %.2fs", endTime.Sub(startTime).Seconds())
@@ -109,42 +146,111 @@ func buildAndCompressTestBin(projPath string, packagePath string, compress bool)
 			log.Printf("Compress bin file %s failed, err: %s", pkgBin, err.Error())
 		}
 	}
-	return nil
+	return false, nil
 }
 
+// isRetryableBuildError 判断编译失败后是否需要重试，编译命令无法启动等非编译错误同样重试，编译被取消或者超时时不再重试
+func isRetryableBuildError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var buildErr *BuildError
+	if errors.As(err, &buildErr) {
+		if !buildErr.Transient {
+			log.Printf("Build package %s failed with non-transient error, skip retrying", buildErr.PackagePath)
+		}
+		return buildErr.Transient
+	}
+	return true
+}
+
+// BuildTestPackage 编译用例包，packagePath为相对于projPath的包路径
+// 编译命令在包所属go模块的根目录下执行，以支持用例库中存在多个go.mod或者go.work的场景
+// 生成的二进制文件位于TESTSOLAR_TTP_BINARYDIR指定的目录下，未指定时与包目录同级，编译成功后记录在编译清单中
+// 编译参数由buildFlags参数以及编译配置文件决定，参与指纹的计算并记录在编译清单中
+// 编译失败时返回*BuildError，其中包含按文件行解析的编译错误
 func BuildTestPackage(projPath string, packagePath string, compress bool) (string, error) {
-	pkgBin := filepath.Join(projPath, packagePath+".test")
-	cmdline := ""
-	if compress {
-		cmdline = fmt.Sprintf("go test -ldflags=\"-s -w\" -c ./%s -o %s", packagePath, pkgBin)
-	} else {
-		cmdline = fmt.Sprintf("go test -c ./%s -o %s", packagePath, pkgBin)
+	return buildTestPackage(context.Background(), projPath, packagePath, compress, nil)
+}
+
+// BuildTestPackageWithContext 与BuildTestPackage相同，ctx被取消或者超时后终止编译进程并返回ctx的错误
+func BuildTestPackageWithContext(ctx context.Context, projPath string, packagePath string, compress bool) (string, error) {
+	return buildTestPackage(ctx, projPath, packagePath, compress, nil)
+}
+
+// buildTestPackage 编译用例包，limiter不为空时编译命令受其并发度限制，编译进程因内存不足被终止时降低并发度后重试
+func buildTestPackage(ctx context.Context, projPath string, packagePath string, compress bool, limiter *buildLimiter) (string, error) {
+	buildFlags, err := compileFlags(projPath, packagePath, compress)
+	if err != nil {
+		log.Printf("Get build flags of package %s failed, err: %v", packagePath, err)
+		return "", err
+	}
+	return compileTestPackage(ctx, projPath, packagePath, compress, buildFlags, packageFingerprint(projPath, packagePath, buildFlags), limiter)
+}
+
+// packageFingerprint 在编译前计算包的指纹，编译过程中源码发生变化时下次编译不会被跳过，计算失败时返回空字符串
+// 计算指纹需要执行`go env`以及`go list`，因此每个包只计算一次，同时用于判断二进制文件是否已是最新以及记录到编译清单中
+func packageFingerprint(projPath, packagePath string, buildFlags []string) string {
+	fingerprint, err := GenFingerprint(projPath, packagePath, buildFlags)
+	if err != nil {
+		log.Printf("Generate fingerprint of %s failed, err: %v", packagePath, err)
 	}
-	log.Printf("Build package %s by cmd: %s", packagePath, cmdline)
+	return fingerprint
+}
+
+// compileTestPackage 按指定的编译参数编译用例包，并将fingerprint记录到编译清单中
+func compileTestPackage(ctx context.Context, projPath string, packagePath string, compress bool, buildFlags []string, fingerprint string, limiter *buildLimiter) (string, error) {
+	pkgBin := BinaryPath(projPath, packagePath)
+	if err := os.MkdirAll(filepath.Dir(pkgBin), 0755); err != nil {
+		return "", err
+	}
+	// 先输出到临时文件，编译成功后再替换原有的二进制文件，编译失败时原有的二进制文件保持不变
+	tmpBin := pkgBin + "." + ginkgoUtil.GenRandomString(8) + ".tmp"
+	defer os.Remove(tmpBin)
+	module := ginkgoUtil.FindPackageModule(projPath, packagePath)
+	modPackagePath := filepath.ToSlash(module.PackagePath)
+	cmdline := "go test -c"
+	for _, flag := range buildFlags {
+		cmdline += " " + cmdpkg.ShellQuote(flag)
+	}
+	cmdline += fmt.Sprintf(" ./%s -o %s", modPackagePath, tmpBin)
+	log.Printf("Build package %s by cmd: %s, module root: %s, envs: %v", packagePath, cmdline, module.Root, module.Envs)
 	err := retry.Do(
 		func() error {
-			_, stderr, err := ginkgoUtil.RunCommandWithOutput(cmdline, projPath)
+			if limiter != nil {
+				limiter.acquire()
+				defer limiter.release()
+			}
+			_, stderr, err := ginkgoUtil.RunCommandWithContextAndOutput(ctx, cmdline, module.Root, module.Envs)
 			if err != nil {
 				log.Printf("Build package %s failed, stderr: %s, err: %s", packagePath, stderr, err.Error())
 				return err
 			}
-			_, err = os.Stat(pkgBin)
+			_, err = os.Stat(tmpBin)
 			if err != nil {
-				log.Printf("Can't find bin file: %s, stderr: %s, err: %s", pkgBin, stderr, err.Error())
-				return err
+				log.Printf("Can't find bin file: %s, stderr: %s, err: %s", tmpBin, stderr, err.Error())
+				buildErr := newBuildError(projPath, module.Root, packagePath, stderr)
+				if buildErr.OutOfMemory && limiter != nil {
+					log.Printf("Build package %s was killed for out of memory, reduce build concurrency to %d", packagePath, limiter.throttle())
+				}
+				return buildErr
 			}
 			return nil
 		},
 		retry.Attempts(MaxExecCmdRetry),
 		retry.Delay(ExecCmdRetryInterval),
+		// 编译错误重试的结果相同，只有依赖下载、文件锁或者内存不足等临时错误需要重试
+		retry.RetryIf(isRetryableBuildError),
+		retry.LastErrorOnly(true),
+		retry.Context(ctx),
 	)
 	if err != nil {
 		log.Printf("Build package %s failed, err: %s", packagePath, err.Error())
 		return "", err
 	}
-	_, err = os.Stat(pkgBin)
+	err = os.Rename(tmpBin, pkgBin)
 	if err != nil {
-		log.Printf("Stat build bin file %s during running failed, err: %v", pkgBin, err)
+		log.Printf("Rename build bin file %s to %s failed, err: %v", tmpBin, pkgBin, err)
 		return "", err
 	}
 	err = os.Chmod(pkgBin, 0777)
@@ -152,5 +258,21 @@ func BuildTestPackage(projPath string, packagePath string, compress bool) (strin
 		log.Printf("Change bin file %s mode failed, err: %v", pkgBin, err)
 		return "", err
 	}
+	version, err := goVersion(projPath, packagePath)
+	if err != nil {
+		log.Printf("Get go version of %s failed, err: %v", packagePath, err)
+	}
+	entry := &ManifestEntry{
+		PackagePath:   packagePath,
+		Binary:        pkgBin,
+		GinkgoVersion: ginkgoUtil.FindGinkgoVersion(filepath.Join(projPath, packagePath)),
+		GoVersion:     version,
+		BuildFlags:    buildFlags,
+		Compress:      compress,
+		Fingerprint:   fingerprint,
+	}
+	if err := updateManifest(projPath, entry); err != nil {
+		log.Printf("Update manifest of %s failed, err: %v", packagePath, err)
+	}
 	return pkgBin, nil
 }
@@ -19,6 +19,12 @@ func Test_build(t *testing.T) {
 	assert.NoError(t, err)
 	err = os.Remove("../../testdata/demo.test")
 	assert.NoError(t, err)
+	// 只包含标准go测试的包同样会被编译
+	err = os.Remove("../../testdata/gotest.test")
+	assert.NoError(t, err)
+	// 只包含testify测试套的包同样会被编译
+	err = os.Remove("../../testdata/testify.test")
+	assert.NoError(t, err)
 	// test build with env
 	err = os.Setenv("TESTSOlAR_TTP_CONCURRENTBUILD", "true")
 	assert.NoError(t, err)
@@ -30,4 +36,6 @@ func Test_build(t *testing.T) {
 	_, err = os.Stat(pkgBin)
 	assert.NoError(t, err)
 	defer os.Remove(pkgBin)
+	defer os.Remove("../../testdata/gotest.test")
+	defer os.Remove("../../testdata/testify.test")
 }
@@ -0,0 +1,83 @@
+package builder
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// concurrentBuildEnvs 旧版本的concurrentBuild参数，其中TESTSOlAR_TTP_CONCURRENTBUILD为早期版本拼写错误的环境变量，保留以兼容已有的配置
+var concurrentBuildEnvs = []string{"TESTSOLAR_TTP_CONCURRENTBUILD", "TESTSOlAR_TTP_CONCURRENTBUILD"}
+
+// BuildConcurrency 返回编译的并发度，通过环境变量TESTSOLAR_TTP_BUILDCONCURRENCY指定，未指定时默认为CPU核数
+// 兼容旧的concurrentBuild参数，未指定并发度且concurrentBuild设置为false时串行编译
+func BuildConcurrency() int {
+	if value := os.Getenv("TESTSOLAR_TTP_BUILDCONCURRENCY"); value != "" {
+		concurrency, err := strconv.Atoi(value)
+		if err == nil && concurrency > 0 {
+			return concurrency
+		}
+		log.Printf("Invalid build concurrency %q, use the default value", value)
+	}
+	for _, env := range concurrentBuildEnvs {
+		value, exists := os.LookupEnv(env)
+		if !exists {
+			continue
+		}
+		if concBuild, err := strconv.ParseBool(value); err == nil && !concBuild {
+			log.Printf("Concurrent building is disabled by %s", env)
+			return 1
+		}
+	}
+	return runtime.NumCPU()
+}
+
+// buildLimiter 限制同时执行的编译命令数，编译进程因内存不足被终止时降低并发度
+type buildLimiter struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	running int
+}
+
+func newBuildLimiter(limit int) *buildLimiter {
+	l := &buildLimiter{limit: limit}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire 等待直到正在执行的编译命令数小于并发度
+func (l *buildLimiter) acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.running >= l.limit {
+		l.cond.Wait()
+	}
+	l.running++
+}
+
+func (l *buildLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	l.cond.Broadcast()
+}
+
+// throttle 将并发度减半，最小为1，返回降低后的并发度；正在执行的编译命令不受影响，新的编译命令需要等待并发度满足要求
+func (l *buildLimiter) throttle() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit > 1 {
+		l.limit /= 2
+	}
+	return l.limit
+}
+
+// current 返回当前的并发度
+func (l *buildLimiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
@@ -0,0 +1,77 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConcurrency(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_BUILDCONCURRENCY", "")
+	t.Setenv("TESTSOLAR_TTP_CONCURRENTBUILD", "")
+	t.Setenv("TESTSOlAR_TTP_CONCURRENTBUILD", "")
+	assert.Equal(t, runtime.NumCPU(), BuildConcurrency())
+
+	t.Setenv("TESTSOLAR_TTP_BUILDCONCURRENCY", "16")
+	assert.Equal(t, 16, BuildConcurrency())
+	t.Setenv("TESTSOLAR_TTP_BUILDCONCURRENCY", "0")
+	assert.Equal(t, runtime.NumCPU(), BuildConcurrency())
+
+	// 兼容旧的concurrentBuild参数以及拼写错误的环境变量
+	t.Setenv("TESTSOlAR_TTP_CONCURRENTBUILD", "false")
+	assert.Equal(t, 1, BuildConcurrency())
+	t.Setenv("TESTSOlAR_TTP_CONCURRENTBUILD", "true")
+	t.Setenv("TESTSOLAR_TTP_CONCURRENTBUILD", "false")
+	assert.Equal(t, 1, BuildConcurrency())
+	t.Setenv("TESTSOLAR_TTP_CONCURRENTBUILD", "true")
+	assert.Equal(t, runtime.NumCPU(), BuildConcurrency())
+	// 指定了并发度时忽略concurrentBuild参数
+	t.Setenv("TESTSOLAR_TTP_CONCURRENTBUILD", "false")
+	t.Setenv("TESTSOLAR_TTP_BUILDCONCURRENCY", "3")
+	assert.Equal(t, 3, BuildConcurrency())
+}
+
+func TestBuildLimiter(t *testing.T) {
+	limiter := newBuildLimiter(2)
+	limiter.acquire()
+	limiter.acquire()
+	var acquired atomic.Bool
+	go func() {
+		limiter.acquire()
+		acquired.Store(true)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, acquired.Load())
+	limiter.release()
+	assert.Eventually(t, acquired.Load, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, limiter.throttle())
+	assert.Equal(t, 1, limiter.throttle())
+	assert.Equal(t, 1, limiter.current())
+}
+
+func TestBuildThrottledOnOOM(t *testing.T) {
+	projPath := t.TempDir()
+	writeModuleFile(t, projPath, "go.mod", "module example.com/oom\n\ngo 1.19\n")
+	writeModuleFile(t, projPath, "app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n")
+	patches := gomonkey.ApplyFunc(ginkgoUtil.RunCommandWithContextAndOutput, func(ctx context.Context, cmdline string, projPath string, envs map[string]string) (string, string, error) {
+		return "", "/usr/local/go/pkg/tool/linux_amd64/link: signal: killed\n", nil
+	})
+	defer patches.Reset()
+	limiter := newBuildLimiter(8)
+	_, err := buildTestPackage(context.Background(), projPath, "app", false, limiter)
+	var buildErr *BuildError
+	require.True(t, errors.As(err, &buildErr))
+	assert.True(t, buildErr.OutOfMemory)
+	// 每次因内存不足失败后并发度减半
+	assert.Equal(t, 8>>MaxExecCmdRetry, limiter.current())
+}
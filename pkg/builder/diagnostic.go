@@ -0,0 +1,129 @@
+package builder
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic `go test -c`输出中定位到具体文件行的编译错误
+type Diagnostic struct {
+	// File 相对于用例库根目录的文件路径
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// Position 返回`file:line`形式的位置，与静态解析错误的命名保持一致
+func (d *Diagnostic) Position() string {
+	return fmt.Sprintf("%s:%d", d.File, d.Line)
+}
+
+// BuildError 编译用例包失败的错误，Diagnostics为从编译输出中解析得到的错误，同一文件同一行的错误合并为一条
+type BuildError struct {
+	PackagePath string
+	Stderr      string
+	Diagnostics []*Diagnostic
+	// Transient 失败原因为依赖下载、文件锁或者内存不足等临时错误，重试可能成功
+	Transient bool
+	// OutOfMemory 编译或者链接进程因内存不足被终止，需要降低编译并发度
+	OutOfMemory bool
+}
+
+func (e *BuildError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return fmt.Sprintf("build package %s failed: %s", e.PackagePath, strings.TrimSpace(e.Stderr))
+	}
+	var messages []string
+	for _, d := range e.Diagnostics {
+		messages = append(messages, fmt.Sprintf("%s: %s", d.Position(), d.Message))
+	}
+	return fmt.Sprintf("build package %s failed: %s", e.PackagePath, strings.Join(messages, "; "))
+}
+
+// diagnosticRegex 匹配`path/foo_test.go:12:5: undefined: x`形式的编译错误，vet错误带有`vet: `前缀
+var diagnosticRegex = regexp.MustCompile(`^(?:vet: )?(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// transientBuildErrorRegexes 可以通过重试恢复的编译失败原因
+var transientBuildErrorRegexes = []*regexp.Regexp{
+	// 依赖下载失败
+	regexp.MustCompile(`dial tcp|i/o timeout|TLS handshake timeout|connection (reset|refused)|no such host|proxyconnect|502 Bad Gateway|503 Service Unavailable|504 Gateway Timeout`),
+	// 模块缓存或者构建缓存的文件锁冲突
+	regexp.MustCompile(`resource temporarily unavailable|text file busy|device or resource busy|\.lock: `),
+	oomBuildErrorRegex,
+}
+
+// oomBuildErrorRegex 编译进程被OOM终止或者内存不足
+var oomBuildErrorRegex = regexp.MustCompile(`signal: killed|out of memory|cannot allocate memory`)
+
+// ParseBuildDiagnostics 解析编译命令的标准错误输出，moduleRoot为执行编译命令的目录
+// 以tab开头的行是上一条错误的补充说明，会追加到上一条错误中
+func ParseBuildDiagnostics(stderr string, moduleRoot string, projPath string) []*Diagnostic {
+	var diagnostics []*Diagnostic
+	byPosition := map[string]*Diagnostic{}
+	var last *Diagnostic
+	for _, line := range strings.Split(stderr, "\n") {
+		if strings.HasPrefix(line, "\t") && last != nil {
+			last.Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		last = nil
+		matches := diagnosticRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(matches[2])
+		column, _ := strconv.Atoi(matches[3])
+		d := &Diagnostic{
+			File:    diagnosticFile(matches[1], moduleRoot, projPath),
+			Line:    lineNo,
+			Column:  column,
+			Message: matches[4],
+		}
+		if exist, ok := byPosition[d.Position()]; ok {
+			exist.Message += "\n" + d.Message
+			last = exist
+			continue
+		}
+		byPosition[d.Position()] = d
+		diagnostics = append(diagnostics, d)
+		last = d
+	}
+	return diagnostics
+}
+
+// diagnosticFile 将编译输出中相对于编译目录的文件路径转换为相对于用例库根目录的路径
+func diagnosticFile(file string, moduleRoot string, projPath string) string {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(moduleRoot, file)
+	}
+	relPath, err := filepath.Rel(projPath, file)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// isTransientBuildError 判断编译失败是否为临时错误
+func isTransientBuildError(stderr string) bool {
+	for _, re := range transientBuildErrorRegexes {
+		if re.MatchString(stderr) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBuildError 根据编译命令的标准错误输出生成编译错误
+func newBuildError(projPath string, moduleRoot string, packagePath string, stderr string) *BuildError {
+	return &BuildError{
+		PackagePath: packagePath,
+		Stderr:      stderr,
+		Diagnostics: ParseBuildDiagnostics(stderr, moduleRoot, projPath),
+		Transient:   isTransientBuildError(stderr),
+		OutOfMemory: oomBuildErrorRegex.MatchString(stderr),
+	}
+}
@@ -0,0 +1,67 @@
+package builder
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBuildDiagnostics(t *testing.T) {
+	stderr := `# demo/pkg/sub [demo/pkg/sub.test]
+pkg/sub/a_test.go:6:6: declared and not used: x
+pkg/sub/a_test.go:6:14: cannot use "s" (untyped string constant) as int value in variable declaration
+pkg/sub/a_test.go:7:2: undefined: undefinedFn
+pkg/sub/b_test.go:3:24: too many return values
+	have (number)
+	want ()
+vet: pkg/sub/c_test.go:9:3: fmt.Printf format %d has arg s of wrong type string
+FAIL	demo/pkg/sub [build failed]
+`
+	diagnostics := ParseBuildDiagnostics(stderr, "/proj/mod", "/proj")
+	assert.Len(t, diagnostics, 4)
+	assert.Equal(t, "mod/pkg/sub/a_test.go:6", diagnostics[0].Position())
+	assert.Equal(t, 6, diagnostics[0].Column)
+	assert.Equal(t, "declared and not used: x\ncannot use \"s\" (untyped string constant) as int value in variable declaration", diagnostics[0].Message)
+	assert.Equal(t, "mod/pkg/sub/a_test.go:7", diagnostics[1].Position())
+	assert.Equal(t, "too many return values\nhave (number)\nwant ()", diagnostics[2].Message)
+	assert.Equal(t, "mod/pkg/sub/c_test.go:9", diagnostics[3].Position())
+
+	assert.Len(t, ParseBuildDiagnostics("go: updates to go.mod needed", "/proj", "/proj"), 0)
+}
+
+func TestIsTransientBuildError(t *testing.T) {
+	assert.True(t, isTransientBuildError(`go: github.com/onsi/ginkgo/v2@v2.1.0: Get "https://proxy.golang.org/...": dial tcp: i/o timeout`))
+	assert.True(t, isTransientBuildError("open /root/go/pkg/mod/cache/download/x.lock: resource temporarily unavailable"))
+	assert.True(t, isTransientBuildError("/usr/local/go/pkg/tool/linux_amd64/compile: signal: killed"))
+	assert.False(t, isTransientBuildError("pkg/sub/a_test.go:7:2: undefined: undefinedFn"))
+}
+
+func TestBuildTestPackageCompileError(t *testing.T) {
+	projPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projPath, "go.mod"), []byte("module broken\n\ngo 1.19\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(projPath, "broken"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projPath, "broken", "broken_test.go"), []byte(`package broken
+
+import "testing"
+
+func TestBroken(t *testing.T) {
+	undefinedFn()
+}
+`), 0644))
+	startTime := time.Now()
+	_, err := BuildTestPackage(projPath, "broken", false)
+	// 编译错误不会重试
+	assert.Less(t, time.Since(startTime), ExecCmdRetryInterval)
+	var buildErr *BuildError
+	require.True(t, errors.As(err, &buildErr))
+	assert.False(t, buildErr.Transient)
+	assert.False(t, buildErr.OutOfMemory)
+	require.Len(t, buildErr.Diagnostics, 1)
+	assert.Equal(t, "broken/broken_test.go:6", buildErr.Diagnostics[0].Position())
+	assert.Equal(t, "undefined: undefinedFn", buildErr.Diagnostics[0].Message)
+}
@@ -0,0 +1,204 @@
+package builder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	"github.com/pkg/errors"
+)
+
+// fingerprintVersion 指纹的计算方式发生变化时需要更新该版本号，使旧的编译记录失效
+const fingerprintVersion = "1"
+
+// 影响编译结果的go环境变量，其值会参与指纹的计算
+var fingerprintGoEnvs = []string{"GOVERSION", "GOOS", "GOARCH", "CGO_ENABLED", "GOFLAGS", "GOEXPERIMENT"}
+
+// listedModule `go list -json`输出的包所属模块
+type listedModule struct {
+	Path    string        `json:"Path"`
+	Version string        `json:"Version"`
+	Replace *listedModule `json:"Replace"`
+}
+
+// listedPackage `go list -json`输出的单个包
+type listedPackage struct {
+	ImportPath      string        `json:"ImportPath"`
+	Dir             string        `json:"Dir"`
+	Standard        bool          `json:"Standard"`
+	Module          *listedModule `json:"Module"`
+	GoFiles         []string      `json:"GoFiles"`
+	CgoFiles        []string      `json:"CgoFiles"`
+	CFiles          []string      `json:"CFiles"`
+	HFiles          []string      `json:"HFiles"`
+	SFiles          []string      `json:"SFiles"`
+	EmbedFiles      []string      `json:"EmbedFiles"`
+	TestGoFiles     []string      `json:"TestGoFiles"`
+	XTestGoFiles    []string      `json:"XTestGoFiles"`
+	TestEmbedFiles  []string      `json:"TestEmbedFiles"`
+	XTestEmbedFiles []string      `json:"XTestEmbedFiles"`
+}
+
+// sourceFiles 返回包内参与编译的所有文件
+func (p *listedPackage) sourceFiles() []string {
+	var files []string
+	for _, group := range [][]string{p.GoFiles, p.CgoFiles, p.CFiles, p.HFiles, p.SFiles, p.EmbedFiles, p.TestGoFiles, p.XTestGoFiles, p.TestEmbedFiles, p.XTestEmbedFiles} {
+		for _, file := range group {
+			files = append(files, filepath.Join(p.Dir, file))
+		}
+	}
+	return files
+}
+
+// moduleVersion 返回模块缓存中依赖的版本，本地目录中的依赖(主模块、本地replace、go.work中的模块)返回空字符串
+func (p *listedPackage) moduleVersion() string {
+	module := p.Module
+	if module == nil {
+		return ""
+	}
+	if module.Replace != nil {
+		module = module.Replace
+	}
+	if module.Version == "" {
+		return ""
+	}
+	return module.Path + "@" + module.Version
+}
+
+// GenFingerprint 计算包的编译指纹，包括go版本及相关环境变量、编译参数以及`go list -deps -test`列出的所有非标准库依赖
+// 模块缓存中的依赖以模块版本参与计算，本地目录中的包以文件内容参与计算
+func GenFingerprint(projPath, packagePath string, buildFlags []string) (string, error) {
+	fingerprint, _, err := GenFingerprintWithSources(projPath, packagePath, buildFlags)
+	return fingerprint, err
+}
+
+// GenFingerprintWithSources 与GenFingerprint相同，同时返回以文件内容参与计算的本地文件(绝对路径，包括主模块的go.mod/go.sum)
+// 调用方可以据此在不执行go命令的情况下判断包及其本地依赖是否发生变化
+func GenFingerprintWithSources(projPath, packagePath string, buildFlags []string) (string, []string, error) {
+	module := ginkgoUtil.FindPackageModule(projPath, packagePath)
+	h := sha256.New()
+	fmt.Fprintf(h, "version:%s\n", fingerprintVersion)
+	goEnvs, err := runGoCommand(module, append([]string{"env"}, fingerprintGoEnvs...)...)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to get go env in %s", module.Root)
+	}
+	fmt.Fprintf(h, "env:%s\n", goEnvs)
+	fmt.Fprintf(h, "flags:%s\n", strings.Join(buildFlags, " "))
+	packages, err := listDeps(module, packagePath)
+	if err != nil {
+		return "", nil, err
+	}
+	modules := map[string]bool{}
+	files := map[string]bool{}
+	for _, pkg := range packages {
+		// 测试二进制的main包由go命令生成，源码位于构建缓存中
+		if pkg.Standard || strings.HasSuffix(pkg.ImportPath, ".test") {
+			continue
+		}
+		if version := pkg.moduleVersion(); version != "" {
+			modules[version] = true
+			continue
+		}
+		for _, file := range pkg.sourceFiles() {
+			files[file] = true
+		}
+	}
+	// 主模块的go.mod/go.sum决定了依赖的版本，同样参与计算
+	for _, name := range []string{"go.mod", "go.sum"} {
+		if exists, _ := ginkgoUtil.FileExists(filepath.Join(module.Root, name)); exists {
+			files[filepath.Join(module.Root, name)] = true
+		}
+	}
+	for _, version := range sortedKeys(modules) {
+		fmt.Fprintf(h, "module:%s\n", version)
+	}
+	sources := sortedKeys(files)
+	for _, file := range sources {
+		if err := hashSourceFile(h, projPath, file); err != nil {
+			return "", nil, err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), sources, nil
+}
+
+// goVersion 返回编译包时使用的go版本
+func goVersion(projPath, packagePath string) (string, error) {
+	version, err := runGoCommand(ginkgoUtil.FindPackageModule(projPath, packagePath), "env", "GOVERSION")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(version), nil
+}
+
+// listDeps 通过`go list -deps -test -json`列出包及其测试代码的所有依赖
+func listDeps(module *ginkgoUtil.PackageModule, packagePath string) ([]*listedPackage, error) {
+	stdout, err := runGoCommand(module, "list", "-deps", "-test", "-json", "./"+filepath.ToSlash(module.PackagePath))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list deps of %s", packagePath)
+	}
+	var packages []*listedPackage
+	decoder := json.NewDecoder(strings.NewReader(stdout))
+	for {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode deps of %s", packagePath)
+		}
+		packages = append(packages, &pkg)
+	}
+	return packages, nil
+}
+
+// runGoCommand 在模块根目录下执行go命令并返回标准输出，`go list -json`的输出较大，不逐行打印到日志中
+func runGoCommand(module *ginkgoUtil.PackageModule, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = module.Root
+	cmd.Env = os.Environ()
+	for k, v := range module.Envs {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "go %s failed, stderr: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return string(stdout), nil
+}
+
+func hashSourceFile(w io.Writer, projPath, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer f.Close()
+	name := path
+	if relPath, err := filepath.Rel(projPath, path); err == nil && !strings.HasPrefix(relPath, "..") {
+		name = filepath.ToSlash(relPath)
+	}
+	fmt.Fprintf(w, "file:%s\n", name)
+	if _, err := io.Copy(w, f); err != nil {
+		return errors.Wrapf(err, "failed to read %s", path)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeModuleFile(t *testing.T, projPath, name, content string) {
+	path := filepath.Join(projPath, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestGenFingerprint(t *testing.T) {
+	projPath := t.TempDir()
+	writeModuleFile(t, projPath, "go.mod", "module example.com/fingerprint\n\ngo 1.19\n")
+	writeModuleFile(t, projPath, "lib/lib.go", "package lib\n\nfunc Value() int { return 1 }\n")
+	writeModuleFile(t, projPath, "app/app_test.go", `package app
+
+import (
+	"testing"
+
+	"example.com/fingerprint/lib"
+)
+
+func TestValue(t *testing.T) {
+	_ = lib.Value()
+}
+`)
+	fingerprint, err := GenFingerprint(projPath, "app", nil)
+	require.NoError(t, err)
+	same, err := GenFingerprint(projPath, "app", nil)
+	require.NoError(t, err)
+	assert.Equal(t, fingerprint, same)
+	withFlags, err := GenFingerprint(projPath, "app", []string{compressLdflags})
+	require.NoError(t, err)
+	assert.NotEqual(t, fingerprint, withFlags)
+
+	// 依赖包的源码变化同样会改变指纹
+	writeModuleFile(t, projPath, "lib/lib.go", "package lib\n\nfunc Value() int { return 2 }\n")
+	changed, err := GenFingerprint(projPath, "app", nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, fingerprint, changed)
+}
+
+func TestIncrementalBuild(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_COMPRESSBINARY", "false")
+	projPath := t.TempDir()
+	writeModuleFile(t, projPath, "go.mod", "module example.com/incremental\n\ngo 1.19\n")
+	writeModuleFile(t, projPath, "app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n")
+	pkgBin := filepath.Join(projPath, "app.test")
+	// 没有编译记录的二进制文件视为过期
+	writeModuleFile(t, projPath, "app.test", "outdated")
+	fingerprint, err := GenFingerprint(projPath, "app", []string{})
+	require.NoError(t, err)
+	assert.True(t, IsBinaryStale(projPath, "app"))
+	assert.False(t, binaryUpToDate(projPath, "app", pkgBin, []string{}, fingerprint))
+
+	require.NoError(t, Build(projPath))
+	fi, err := os.Stat(pkgBin)
+	require.NoError(t, err)
+	assert.False(t, IsBinaryStale(projPath, "app"))
+	assert.True(t, binaryUpToDate(projPath, "app", pkgBin, []string{}, fingerprint))
+	assert.False(t, binaryUpToDate(projPath, "app", pkgBin, []string{compressLdflags}, fingerprint))
+	// 无法计算指纹时不跳过编译
+	assert.False(t, binaryUpToDate(projPath, "app", pkgBin, []string{}, ""))
+
+	// 源码未变化时跳过编译
+	modTime := fi.ModTime()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, Build(projPath))
+	fi, err = os.Stat(pkgBin)
+	require.NoError(t, err)
+	assert.Equal(t, modTime, fi.ModTime())
+
+	// 源码变化后二进制文件过期，重新编译
+	writeModuleFile(t, projPath, "app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n\nfunc TestMore(t *testing.T) {}\n")
+	assert.True(t, IsBinaryStale(projPath, "app"))
+	require.NoError(t, Build(projPath))
+	fi, err = os.Stat(pkgBin)
+	require.NoError(t, err)
+	assert.NotEqual(t, modTime, fi.ModTime())
+	assert.False(t, IsBinaryStale(projPath, "app"))
+
+	// 只提供二进制文件时无法判断，直接使用
+	assert.False(t, IsBinaryStale(projPath, "not_exist"))
+}
+
+func TestBuildGenFingerprintOnce(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_COMPRESSBINARY", "false")
+	projPath := t.TempDir()
+	writeModuleFile(t, projPath, "go.mod", "module example.com/once\n\ngo 1.19\n")
+	writeModuleFile(t, projPath, "app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n")
+	var count int
+	patches := gomonkey.ApplyFunc(GenFingerprintWithSources, func(projPath, packagePath string, buildFlags []string) (string, []string, error) {
+		count++
+		return "fingerprint", nil, nil
+	})
+	defer patches.Reset()
+	// 判断二进制文件是否已是最新与写入编译清单共用同一次计算的指纹
+	skipped, err := buildAndCompressTestBin(projPath, "app", false, nil)
+	require.NoError(t, err)
+	assert.False(t, skipped)
+	assert.Equal(t, 1, count)
+	skipped, err = buildAndCompressTestBin(projPath, "app", false, nil)
+	require.NoError(t, err)
+	assert.True(t, skipped)
+	assert.Equal(t, 2, count)
+}
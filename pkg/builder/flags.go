@@ -0,0 +1,137 @@
+package builder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ginkgoCoverage "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/coverage"
+
+	"github.com/google/shlex"
+	"github.com/pkg/errors"
+)
+
+// compressLdflags 压缩二进制文件时去除符号表和调试信息的链接参数
+const compressLdflags = "-ldflags=-s -w"
+
+// BuildConfig 编译配置文件，通过环境变量TESTSOLAR_TTP_BUILDCONFIG指定，相对路径相对于用例库根目录
+//
+//	{
+//	  "packages": {
+//	    "test/integration": "-tags integration",
+//	    "test/e2e/...": "-race -tags e2e"
+//	  }
+//	}
+//
+// Packages的键为相对于用例库根目录的包路径，以`/...`结尾时匹配该目录及其所有子目录，值为该包的编译参数，会替代buildFlags参数
+type BuildConfig struct {
+	Packages map[string]string `json:"packages"`
+}
+
+// ReadBuildConfig 读取编译配置文件，未指定配置文件时返回空配置
+func ReadBuildConfig(projPath string) (*BuildConfig, error) {
+	config := &BuildConfig{Packages: map[string]string{}}
+	configFile := os.Getenv("TESTSOLAR_TTP_BUILDCONFIG")
+	if configFile == "" {
+		return config, nil
+	}
+	if !filepath.IsAbs(configFile) {
+		configFile = filepath.Join(projPath, configFile)
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read build config %s", configFile)
+	}
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal build config %s", configFile)
+	}
+	if config.Packages == nil {
+		config.Packages = map[string]string{}
+	}
+	return config, nil
+}
+
+// packageFlags 返回配置文件中与包匹配的编译参数，多个配置匹配时使用路径最长的配置
+func (c *BuildConfig) packageFlags(packagePath string) (string, bool) {
+	packagePath = manifestKey(packagePath)
+	var patterns []string
+	for pattern := range c.Packages {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if matchPackagePattern(pattern, packagePath) {
+			return c.Packages[pattern], true
+		}
+	}
+	return "", false
+}
+
+// matchPackagePattern 判断包路径是否与配置中的包路径匹配，`./...`以及`...`匹配所有包
+func matchPackagePattern(pattern, packagePath string) bool {
+	pattern = filepath.ToSlash(pattern)
+	if strings.HasSuffix(pattern, "...") {
+		prefix := manifestKey(strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/"))
+		return prefix == "" || packagePath == prefix || strings.HasPrefix(packagePath, prefix+"/")
+	}
+	return manifestKey(pattern) == packagePath
+}
+
+// PackageBuildFlags 返回包的编译参数，配置文件中有匹配的配置时使用配置文件中的参数，否则使用环境变量TESTSOLAR_TTP_BUILDFLAGS指定的参数
+func PackageBuildFlags(projPath, packagePath string) ([]string, error) {
+	config, err := ReadBuildConfig(projPath)
+	if err != nil {
+		return nil, err
+	}
+	flags, ok := config.packageFlags(packagePath)
+	if !ok {
+		flags = os.Getenv("TESTSOLAR_TTP_BUILDFLAGS")
+	}
+	buildFlags, err := shlex.Split(flags)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse build flags of %s: %s", packagePath, flags)
+	}
+	if buildFlags == nil {
+		buildFlags = []string{}
+	}
+	return buildFlags, nil
+}
+
+// compileFlags 返回编译命令中影响二进制文件内容的参数，压缩二进制文件时追加去除符号表的链接参数，用户指定了-ldflags时保留用户的参数
+// 开启覆盖率统计时追加-cover参数
+func compileFlags(projPath, packagePath string, compress bool) ([]string, error) {
+	buildFlags, err := PackageBuildFlags(projPath, packagePath)
+	if err != nil {
+		return nil, err
+	}
+	if ginkgoCoverage.Enabled() && !hasCoverFlag(buildFlags) {
+		buildFlags = append(buildFlags, "-cover")
+	}
+	if compress && !hasFlag(buildFlags, "ldflags") {
+		buildFlags = append(buildFlags, compressLdflags)
+	}
+	return buildFlags, nil
+}
+
+// hasCoverFlag 判断编译参数是否开启了覆盖率统计，-covermode与-coverpkg同样会开启覆盖率统计
+func hasCoverFlag(buildFlags []string) bool {
+	return hasFlag(buildFlags, "cover") || hasFlag(buildFlags, "covermode") || hasFlag(buildFlags, "coverpkg")
+}
+
+// hasFlag 判断参数列表中是否包含指定参数，兼容`-flag`与`--flag`以及`-flag=value`与`-flag value`的写法
+func hasFlag(flags []string, name string) bool {
+	for _, flag := range flags {
+		flag = strings.TrimPrefix(strings.TrimPrefix(flag, "-"), "-")
+		if flag == name || strings.HasPrefix(flag, name+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package builder
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageBuildFlags(t *testing.T) {
+	projPath := t.TempDir()
+	flags, err := PackageBuildFlags(projPath, "app")
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, flags)
+
+	t.Setenv("TESTSOLAR_TTP_BUILDFLAGS", `-race -gcflags="all=-N -l"`)
+	flags, err = PackageBuildFlags(projPath, "app")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-race", "-gcflags=all=-N -l"}, flags)
+
+	writeModuleFile(t, projPath, "build.json", `{
+  "packages": {
+    "test/...": "-tags integration",
+    "test/e2e/...": "-tags e2e -trimpath",
+    "app": ""
+  }
+}`)
+	t.Setenv("TESTSOLAR_TTP_BUILDCONFIG", "build.json")
+	for packagePath, expected := range map[string][]string{
+		"test":           {"-tags", "integration"},
+		"test/api":       {"-tags", "integration"},
+		"test/e2e":       {"-tags", "e2e", "-trimpath"},
+		"test/e2e/login": {"-tags", "e2e", "-trimpath"},
+		"testing":        {"-race", "-gcflags=all=-N -l"},
+		"app":            {},
+	} {
+		flags, err := PackageBuildFlags(projPath, packagePath)
+		require.NoError(t, err)
+		assert.Equal(t, expected, flags, packagePath)
+	}
+
+	// 压缩时追加去除符号表的链接参数，用户指定了-ldflags时保留用户的参数
+	flags, err = compileFlags(projPath, "app", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{compressLdflags}, flags)
+	t.Setenv("TESTSOLAR_TTP_BUILDFLAGS", "-ldflags '-X main.version=1'")
+	flags, err = compileFlags(projPath, "other", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-ldflags", "-X main.version=1"}, flags)
+
+	t.Setenv("TESTSOLAR_TTP_BUILDCONFIG", "not_exist.json")
+	_, err = PackageBuildFlags(projPath, "app")
+	assert.Error(t, err)
+}
+
+func TestBuildWithFlags(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_COMPRESSBINARY", "false")
+	projPath := t.TempDir()
+	writeModuleFile(t, projPath, "go.mod", "module example.com/flags\n\ngo 1.19\n")
+	writeModuleFile(t, projPath, "app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n")
+	writeModuleFile(t, projPath, "app/integration_test.go", "//go:build integration\n\npackage app\n\nimport \"testing\"\n\nfunc TestIntegration(t *testing.T) {}\n")
+	listTests := func(pkgBin string) string {
+		output, err := exec.Command(pkgBin, "-test.list", ".").Output()
+		require.NoError(t, err)
+		return string(output)
+	}
+
+	t.Setenv("TESTSOLAR_TTP_BUILDFLAGS", "-tags integration")
+	pkgBin, err := BuildTestPackage(projPath, "app", false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(projPath, "app.test"), pkgBin)
+	assert.Contains(t, listTests(pkgBin), "TestIntegration")
+	entry := lookupManifest(projPath, "app")
+	require.NotNil(t, entry)
+	assert.Equal(t, []string{"-tags", "integration"}, entry.BuildFlags)
+	assert.Equal(t, []string{"-tags", "integration"}, BinaryBuildFlags(projPath, "app"))
+	assert.False(t, IsBinaryStale(projPath, "app"))
+
+	// 编译参数变化后二进制文件过期，重新编译时使用新的参数
+	t.Setenv("TESTSOLAR_TTP_BUILDFLAGS", "")
+	assert.True(t, IsBinaryStale(projPath, "app"))
+	require.NoError(t, Build(projPath))
+	assert.NotContains(t, listTests(pkgBin), "TestIntegration")
+	assert.False(t, IsBinaryStale(projPath, "app"))
+}
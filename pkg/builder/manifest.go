@@ -0,0 +1,259 @@
+package builder
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestFileName 编译清单的文件名，位于二进制文件输出目录下
+const ManifestFileName = "manifest.json"
+
+// defaultManifestDir 未指定二进制文件输出目录时编译清单所在的目录，相对于用例库根目录
+var defaultManifestDir = filepath.Join(".testtool", "build")
+
+// manifestLock 并发编译时串行更新编译清单
+var manifestLock sync.Mutex
+
+// Manifest 编译清单，记录每个包编译生成的二进制文件以及编译时的环境
+type Manifest struct {
+	Packages map[string]*ManifestEntry `json:"packages"`
+}
+
+// ManifestEntry 单个包的编译记录
+type ManifestEntry struct {
+	// PackagePath 相对于用例库根目录的包路径
+	PackagePath string `json:"packagePath"`
+	// Binary 二进制文件路径，位于用例库内时为相对于用例库根目录的路径
+	Binary        string   `json:"binary"`
+	GinkgoVersion int      `json:"ginkgoVersion"`
+	GoVersion     string   `json:"goVersion"`
+	BuildFlags    []string `json:"buildFlags"`
+	// Compress 编译时是否压缩了二进制文件，压缩时编译参数中追加了去除符号表的链接参数
+	Compress bool `json:"compress,omitempty"`
+	// Fingerprint 编译时源码、依赖以及编译参数的指纹
+	Fingerprint string `json:"fingerprint"`
+}
+
+// BinaryDir 通过环境变量TESTSOLAR_TTP_BINARYDIR指定的二进制文件输出目录，相对路径相对于用例库根目录
+// 未指定时返回空字符串，二进制文件与包目录同级生成
+func BinaryDir(projPath string) string {
+	binaryDir := os.Getenv("TESTSOLAR_TTP_BINARYDIR")
+	if binaryDir == "" {
+		return ""
+	}
+	if !filepath.IsAbs(binaryDir) {
+		binaryDir = filepath.Join(projPath, binaryDir)
+	}
+	return binaryDir
+}
+
+// BinaryPath 返回包编译生成的二进制文件路径
+func BinaryPath(projPath, packagePath string) string {
+	if binaryDir := BinaryDir(projPath); binaryDir != "" {
+		return filepath.Join(binaryDir, packagePath+".test")
+	}
+	return filepath.Join(projPath, packagePath+".test")
+}
+
+// ManifestFile 返回编译清单的路径
+func ManifestFile(projPath string) string {
+	if binaryDir := BinaryDir(projPath); binaryDir != "" {
+		return filepath.Join(binaryDir, ManifestFileName)
+	}
+	return filepath.Join(projPath, defaultManifestDir, ManifestFileName)
+}
+
+// ReadManifest 读取编译清单，清单不存在时返回空清单
+func ReadManifest(projPath string) (*Manifest, error) {
+	manifest := &Manifest{Packages: map[string]*ManifestEntry{}}
+	content, err := os.ReadFile(ManifestFile(projPath))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest %s", ManifestFile(projPath))
+	}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal manifest %s", ManifestFile(projPath))
+	}
+	if manifest.Packages == nil {
+		manifest.Packages = map[string]*ManifestEntry{}
+	}
+	return manifest, nil
+}
+
+// Lookup 查找包的编译记录，不存在时返回nil
+func (m *Manifest) Lookup(packagePath string) *ManifestEntry {
+	return m.Packages[manifestKey(packagePath)]
+}
+
+// FindPackage 在清单中查找路径所属的包，即路径本身或者最近的上层目录，用于用例源码不存在而只提供了二进制文件的场景
+func (m *Manifest) FindPackage(path string) (string, bool) {
+	path = manifestKey(path)
+	for {
+		if _, ok := m.Packages[path]; ok {
+			return path, true
+		}
+		if path == "" {
+			return "", false
+		}
+		parent := filepath.ToSlash(filepath.Dir(path))
+		if parent == "." || parent == "/" || parent == path {
+			parent = ""
+		}
+		path = parent
+	}
+}
+
+// manifestKey 统一清单中包路径的格式
+func manifestKey(packagePath string) string {
+	key := filepath.ToSlash(filepath.Clean(packagePath))
+	if key == "." {
+		return ""
+	}
+	return strings.TrimSuffix(key, "/")
+}
+
+// BinaryPath 返回编译记录中二进制文件的绝对路径
+func (e *ManifestEntry) BinaryPath(projPath string) string {
+	if filepath.IsAbs(e.Binary) {
+		return e.Binary
+	}
+	return filepath.Join(projPath, filepath.FromSlash(e.Binary))
+}
+
+// updateManifest 更新包的编译记录，先写入临时文件再重命名，避免并发读取到不完整的内容
+func updateManifest(projPath string, entry *ManifestEntry) error {
+	manifestLock.Lock()
+	defer manifestLock.Unlock()
+	manifest, err := ReadManifest(projPath)
+	if err != nil {
+		log.Printf("Read manifest failed, recreate it, err: %v", err)
+		manifest = &Manifest{Packages: map[string]*ManifestEntry{}}
+	}
+	if relPath, err := filepath.Rel(projPath, entry.Binary); err == nil && !strings.HasPrefix(relPath, "..") {
+		entry.Binary = filepath.ToSlash(relPath)
+	}
+	entry.PackagePath = manifestKey(entry.PackagePath)
+	manifest.Packages[entry.PackagePath] = entry
+	manifestFile := ManifestFile(projPath)
+	if err := os.MkdirAll(filepath.Dir(manifestFile), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create manifest dir")
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal manifest")
+	}
+	tmpFile := manifestFile + "." + ginkgoUtil.GenRandomString(8) + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write manifest %s", tmpFile)
+	}
+	if err := os.Rename(tmpFile, manifestFile); err != nil {
+		_ = os.Remove(tmpFile)
+		return errors.Wrapf(err, "failed to write manifest %s", manifestFile)
+	}
+	return nil
+}
+
+// lookupManifest 读取包的编译记录，清单或者记录不存在时返回nil
+func lookupManifest(projPath, packagePath string) *ManifestEntry {
+	manifest, err := ReadManifest(projPath)
+	if err != nil {
+		log.Printf("Read manifest failed, err: %v", err)
+		return nil
+	}
+	return manifest.Lookup(packagePath)
+}
+
+// ResolveBinary 返回包的二进制文件，优先使用编译清单中记录的二进制文件
+// 清单中没有记录时使用与包目录同级的`<pkg>.test`，以兼容未通过插件编译的二进制文件，均不存在时返回空字符串
+func ResolveBinary(projPath, packagePath string) string {
+	if entry := lookupManifest(projPath, packagePath); entry != nil {
+		pkgBin := entry.BinaryPath(projPath)
+		if _, err := os.Stat(pkgBin); err == nil {
+			return pkgBin
+		}
+		log.Printf("Bin file %s of %s recorded in manifest does not exist", pkgBin, packagePath)
+	}
+	pkgBin := filepath.Join(projPath, packagePath+".test")
+	if _, err := os.Stat(pkgBin); err == nil {
+		return pkgBin
+	}
+	return ""
+}
+
+// BinaryBuildFlags 返回包的二进制文件编译时使用的编译参数，编译清单中没有记录时返回当前配置的编译参数
+func BinaryBuildFlags(projPath, packagePath string) []string {
+	if entry := lookupManifest(projPath, packagePath); entry != nil {
+		return entry.BuildFlags
+	}
+	buildFlags, err := PackageBuildFlags(projPath, packagePath)
+	if err != nil {
+		log.Printf("Get build flags of %s failed, err: %v", packagePath, err)
+		return nil
+	}
+	return buildFlags
+}
+
+// IsCoverBinary 判断包的二进制文件是否开启了覆盖率统计，只有开启了覆盖率统计的二进制文件才能通过-test.gocoverdir输出覆盖率数据
+func IsCoverBinary(projPath, packagePath string) bool {
+	entry := lookupManifest(projPath, packagePath)
+	return entry != nil && hasCoverFlag(entry.BuildFlags)
+}
+
+// PackageGinkgoVersion 返回包使用的ginkgo版本，优先使用编译清单中的记录，以支持只提供了二进制文件而没有源码的场景
+func PackageGinkgoVersion(projPath, packagePath string) int {
+	if entry := lookupManifest(projPath, packagePath); entry != nil && entry.GinkgoVersion > 0 {
+		return entry.GinkgoVersion
+	}
+	return ginkgoUtil.FindGinkgoVersion(filepath.Join(projPath, packagePath))
+}
+
+// binaryUpToDate 判断包已有的二进制文件是否由相同的源码、依赖以及编译参数生成，fingerprint为包当前的指纹，为空时表示无法计算指纹
+func binaryUpToDate(projPath, packagePath, pkgBin string, buildFlags []string, fingerprint string) bool {
+	if fingerprint == "" {
+		return false
+	}
+	if _, err := os.Stat(pkgBin); err != nil {
+		return false
+	}
+	entry := lookupManifest(projPath, packagePath)
+	if entry == nil || entry.BinaryPath(projPath) != pkgBin || strings.Join(entry.BuildFlags, " ") != strings.Join(buildFlags, " ") {
+		return false
+	}
+	return fingerprint == entry.Fingerprint
+}
+
+// IsBinaryStale 判断预编译的二进制文件是否已经过期，用于执行用例前决定是否需要重新编译，编译参数发生变化时同样认为已经过期
+// 包的源码不存在(只提供了二进制文件)或者无法计算指纹时认为二进制文件可用；清单中没有记录时无法确认二进制文件的来源，认为已经过期
+func IsBinaryStale(projPath, packagePath string) bool {
+	if testFiles, _ := filepath.Glob(filepath.Join(projPath, packagePath, "*_test.go")); len(testFiles) == 0 {
+		return false
+	}
+	entry := lookupManifest(projPath, packagePath)
+	if entry == nil {
+		log.Printf("Can't find %s in manifest", packagePath)
+		return true
+	}
+	if buildFlags, err := compileFlags(projPath, packagePath, entry.Compress); err != nil {
+		log.Printf("Get build flags of %s failed, use the existing binary, err: %v", packagePath, err)
+		return false
+	} else if strings.Join(buildFlags, " ") != strings.Join(entry.BuildFlags, " ") {
+		log.Printf("Build flags of %s changed from %v to %v", packagePath, entry.BuildFlags, buildFlags)
+		return true
+	}
+	fingerprint, err := GenFingerprint(projPath, packagePath, entry.BuildFlags)
+	if err != nil {
+		log.Printf("Generate fingerprint of %s failed, use the existing binary, err: %v", packagePath, err)
+		return false
+	}
+	return fingerprint != entry.Fingerprint
+}
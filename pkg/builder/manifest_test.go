@@ -0,0 +1,57 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildManifest(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_COMPRESSBINARY", "false")
+	projPath := t.TempDir()
+	writeModuleFile(t, projPath, "go.mod", "module example.com/manifest\n\ngo 1.19\n")
+	writeModuleFile(t, projPath, "app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n")
+	writeModuleFile(t, projPath, "app/sub/sub_test.go", "package sub\n\nimport \"testing\"\n\nfunc TestSub(t *testing.T) {}\n")
+
+	// 未指定输出目录时二进制文件与包目录同级，清单位于.testtool/build下
+	assert.Equal(t, filepath.Join(projPath, "app.test"), BinaryPath(projPath, "app"))
+	assert.Equal(t, filepath.Join(projPath, ".testtool", "build", ManifestFileName), ManifestFile(projPath))
+
+	t.Setenv("TESTSOLAR_TTP_BINARYDIR", "out")
+	require.NoError(t, Build(projPath))
+	assert.NoFileExists(t, filepath.Join(projPath, "app.test"))
+	assert.FileExists(t, filepath.Join(projPath, "out", "app.test"))
+	assert.FileExists(t, filepath.Join(projPath, "out", "app", "sub.test"))
+	assert.FileExists(t, filepath.Join(projPath, "out", ManifestFileName))
+
+	manifest, err := ReadManifest(projPath)
+	require.NoError(t, err)
+	assert.Len(t, manifest.Packages, 2)
+	entry := manifest.Lookup("app/sub")
+	require.NotNil(t, entry)
+	assert.Equal(t, "app/sub", entry.PackagePath)
+	assert.Equal(t, "out/app/sub.test", entry.Binary)
+	assert.NotEmpty(t, entry.GoVersion)
+	assert.NotEmpty(t, entry.Fingerprint)
+	assert.Equal(t, []string{}, entry.BuildFlags)
+	packagePath, ok := manifest.FindPackage("app/sub/nested")
+	assert.True(t, ok)
+	assert.Equal(t, "app/sub", packagePath)
+	_, ok = manifest.FindPackage("other")
+	assert.False(t, ok)
+
+	assert.Equal(t, filepath.Join(projPath, "out", "app", "sub.test"), ResolveBinary(projPath, "app/sub"))
+	assert.Equal(t, "", ResolveBinary(projPath, "other"))
+	// 清单中没有记录时使用与包目录同级的二进制文件
+	writeModuleFile(t, projPath, "legacy.test", "")
+	assert.Equal(t, filepath.Join(projPath, "legacy.test"), ResolveBinary(projPath, "legacy"))
+
+	// 清单中记录的ginkgo版本在源码不存在时依然可用
+	entry.GinkgoVersion = 2
+	require.NoError(t, updateManifest(projPath, entry))
+	require.NoError(t, os.RemoveAll(filepath.Join(projPath, "app", "sub")))
+	assert.Equal(t, 2, PackageGinkgoVersion(projPath, "app/sub"))
+}
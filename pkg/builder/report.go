@@ -0,0 +1,184 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// BuildReportFileName 编译报告的文件名，与编译清单位于同一目录
+const BuildReportFileName = "build-report.json"
+
+// BuildStatus 单个包的编译状态
+type BuildStatus string
+
+const (
+	BuildStatusSucceeded BuildStatus = "succeeded"
+	BuildStatusFailed    BuildStatus = "failed"
+	// BuildStatusUpToDate 指纹未变化，复用已有的二进制文件
+	BuildStatusUpToDate BuildStatus = "upToDate"
+)
+
+// PackageBuildResult 单个包的编译结果
+type PackageBuildResult struct {
+	PackagePath string      `json:"packagePath"`
+	Status      BuildStatus `json:"status"`
+	// Duration 编译耗时，单位为秒
+	Duration    float64       `json:"duration"`
+	Binary      string        `json:"binary,omitempty"`
+	BinarySize  int64         `json:"binarySize,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Diagnostics []*Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// BuildReport 一次编译的报告
+type BuildReport struct {
+	StartTime time.Time `json:"startTime"`
+	// Duration 总耗时，单位为秒
+	Duration float64 `json:"duration"`
+	Total    int     `json:"total"`
+	Failed   int     `json:"failed"`
+	// Concurrency 编译开始时的并发度，FinalConcurrency为因内存不足降低后的并发度
+	Concurrency      int                   `json:"concurrency"`
+	FinalConcurrency int                   `json:"finalConcurrency"`
+	Packages         []*PackageBuildResult `json:"packages"`
+}
+
+// BuildReportFile 返回编译报告的路径
+func BuildReportFile(projPath string) string {
+	return filepath.Join(filepath.Dir(ManifestFile(projPath)), BuildReportFileName)
+}
+
+// newPackageBuildResult 根据编译结果生成包的编译记录
+func newPackageBuildResult(projPath, packagePath string, upToDate bool, duration time.Duration, err error) *PackageBuildResult {
+	result := &PackageBuildResult{
+		PackagePath: packagePath,
+		Status:      BuildStatusSucceeded,
+		Duration:    duration.Seconds(),
+	}
+	if err != nil {
+		result.Status = BuildStatusFailed
+		result.Error = err.Error()
+		var buildErr *BuildError
+		if errors.As(err, &buildErr) {
+			result.Diagnostics = buildErr.Diagnostics
+		}
+		return result
+	}
+	if upToDate {
+		result.Status = BuildStatusUpToDate
+	}
+	result.Binary = BinaryPath(projPath, packagePath)
+	if fi, err := os.Stat(result.Binary); err == nil {
+		result.BinarySize = fi.Size()
+	}
+	return result
+}
+
+// finishedPackages 返回已经完成编译的包的记录，未开启continueOnError时编译失败后可能有包没有编译记录
+func (r *BuildReport) finishedPackages() []*PackageBuildResult {
+	results := make([]*PackageBuildResult, 0, len(r.Packages))
+	for _, result := range r.Packages {
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// FailedPackages 返回编译失败的包
+func (r *BuildReport) FailedPackages() []string {
+	var packages []string
+	for _, result := range r.finishedPackages() {
+		if result.Status == BuildStatusFailed {
+			packages = append(packages, displayPackagePath(result.PackagePath))
+		}
+	}
+	return packages
+}
+
+// logTimings 按耗时从高到低打印每个包的编译耗时，用于调整编译并发度
+func (r *BuildReport) logTimings() {
+	results := r.finishedPackages()
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Duration > results[j].Duration
+	})
+	log.Printf("Build %d packages cost %.2fs, concurrency %d, final concurrency %d", r.Total, r.Duration, r.Concurrency, r.FinalConcurrency)
+	for _, result := range results {
+		log.Printf("  %-50s %-10s %8.2fs", displayPackagePath(result.PackagePath), result.Status, result.Duration)
+	}
+}
+
+// Save 将编译报告写入文件
+func (r *BuildReport) Save(reportFile string) error {
+	if err := os.MkdirAll(filepath.Dir(reportFile), 0755); err != nil {
+		return pkgErrors.Wrapf(err, "failed to create build report dir")
+	}
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return pkgErrors.Wrapf(err, "failed to marshal build report")
+	}
+	if err := os.WriteFile(reportFile, content, 0644); err != nil {
+		return pkgErrors.Wrapf(err, "failed to write build report %s", reportFile)
+	}
+	return nil
+}
+
+// WriteTable 以表格形式输出编译报告，编译失败的包只输出第一条错误
+func (r *BuildReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tSTATUS\tDURATION\tSIZE\tERROR")
+	for _, result := range r.finishedPackages() {
+		fmt.Fprintf(tw, "%s\t%s\t%.2fs\t%s\t%s\n",
+			displayPackagePath(result.PackagePath), result.Status, result.Duration, formatSize(result.BinarySize), firstError(result))
+	}
+	fmt.Fprintf(tw, "TOTAL %d\tFAILED %d\t%.2fs\t\t\n", r.Total, r.Failed, r.Duration)
+	return tw.Flush()
+}
+
+func displayPackagePath(packagePath string) string {
+	if packagePath == "" {
+		return "."
+	}
+	return packagePath
+}
+
+func firstError(result *PackageBuildResult) string {
+	message := result.Error
+	if len(result.Diagnostics) > 0 {
+		d := result.Diagnostics[0]
+		message = d.Position() + ": " + d.Message
+	}
+	message = strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	return ginkgoUtil.ShortenString(message, 120)
+}
+
+func formatSize(size int64) string {
+	if size <= 0 {
+		return "-"
+	}
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	value := float64(size)
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		value /= unit
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f%s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
@@ -0,0 +1,115 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeBuildReportModule(t *testing.T) string {
+	projPath := t.TempDir()
+	writeModuleFile(t, projPath, "go.mod", "module example.com/report\n\ngo 1.19\n")
+	writeModuleFile(t, projPath, "a/a_test.go", "package a\n\nimport \"testing\"\n\nfunc TestA(t *testing.T) {}\n")
+	writeModuleFile(t, projPath, "b/b_test.go", "package b\n\nimport \"testing\"\n\nfunc TestB(t *testing.T) {\n\tundefinedFunc()\n}\n")
+	writeModuleFile(t, projPath, "c/c_test.go", "package c\n\nimport \"testing\"\n\nfunc TestC(t *testing.T) {}\n")
+	return projPath
+}
+
+func readBuildReport(t *testing.T, projPath string) *BuildReport {
+	content, err := os.ReadFile(BuildReportFile(projPath))
+	require.NoError(t, err)
+	var report BuildReport
+	require.NoError(t, json.Unmarshal(content, &report))
+	return &report
+}
+
+func TestBuildContinueOnError(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_COMPRESSBINARY", "false")
+	t.Setenv("TESTSOLAR_TTP_BUILDCONCURRENCY", "1")
+	t.Setenv("TESTSOLAR_TTP_CONTINUEONERROR", "true")
+	projPath := writeBuildReportModule(t)
+	err := Build(projPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "build 1 of 3 packages failed: b")
+	assert.FileExists(t, filepath.Join(projPath, "a.test"))
+	assert.FileExists(t, filepath.Join(projPath, "c.test"))
+
+	report := readBuildReport(t, projPath)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Packages, 3)
+	statuses := map[string]BuildStatus{}
+	for _, result := range report.Packages {
+		statuses[result.PackagePath] = result.Status
+	}
+	assert.Equal(t, map[string]BuildStatus{"a": BuildStatusSucceeded, "b": BuildStatusFailed, "c": BuildStatusSucceeded}, statuses)
+	for _, result := range report.Packages {
+		if result.PackagePath == "b" {
+			assert.Empty(t, result.Binary)
+			require.Len(t, result.Diagnostics, 1)
+			assert.Equal(t, "b/b_test.go:6", result.Diagnostics[0].Position())
+		} else {
+			assert.Greater(t, result.BinarySize, int64(0))
+		}
+	}
+
+	// 再次编译时未变化的包复用已有的二进制文件
+	err = Build(projPath)
+	require.Error(t, err)
+	report = readBuildReport(t, projPath)
+	for _, result := range report.Packages {
+		if result.PackagePath != "b" {
+			assert.Equal(t, BuildStatusUpToDate, result.Status)
+		}
+	}
+}
+
+func TestBuildDefaultBuildsAllPackages(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_COMPRESSBINARY", "false")
+	t.Setenv("TESTSOLAR_TTP_BUILDCONCURRENCY", "1")
+	t.Setenv("TESTSOLAR_TTP_CONTINUEONERROR", "false")
+	projPath := writeBuildReportModule(t)
+	err := Build(projPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "build package failed")
+	report := readBuildReport(t, projPath)
+	var statuses []BuildStatus
+	for _, result := range report.Packages {
+		statuses = append(statuses, result.Status)
+	}
+	// 未开启continueOnError时同样编译所有包，只返回第一个编译失败的错误
+	assert.Equal(t, []BuildStatus{BuildStatusSucceeded, BuildStatusFailed, BuildStatusSucceeded}, statuses)
+	assert.FileExists(t, filepath.Join(projPath, "c.test"))
+}
+
+func TestBuildReportTable(t *testing.T) {
+	report := &BuildReport{
+		Total:    2,
+		Failed:   1,
+		Duration: 3.5,
+		Packages: []*PackageBuildResult{
+			{PackagePath: "", Status: BuildStatusSucceeded, Duration: 1.25, BinarySize: 3 * 1024 * 1024},
+			{PackagePath: "b", Status: BuildStatusFailed, Duration: 2, Error: "build package b failed", Diagnostics: []*Diagnostic{{File: "b/b_test.go", Line: 6, Message: "undefined: undefinedFunc\nmore"}}},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteTable(&buf))
+	table := buf.String()
+	assert.Contains(t, table, "PACKAGE")
+	assert.Regexp(t, `\.\s+succeeded\s+1\.25s\s+3\.0MB`, table)
+	assert.Regexp(t, `b\s+failed\s+2\.00s\s+-\s+b/b_test.go:6: undefined: undefinedFunc\n`, table)
+	assert.Equal(t, []string{"b"}, report.FailedPackages())
+	// 没有编译记录的包不会输出
+	report.Packages = append(report.Packages, nil)
+	buf.Reset()
+	require.NoError(t, report.WriteTable(&buf))
+	assert.Equal(t, table, buf.String())
+	assert.Equal(t, []string{"b"}, report.FailedPackages())
+	assert.Equal(t, "512B", formatSize(512))
+	assert.Equal(t, "1.5KB", formatSize(1536))
+}
@@ -49,3 +49,48 @@ func GenTestCaseFocusName(tcNames []string) string {
 func ExtractPackPathFromBinFile(pkgBin, projPath string) string {
 	return strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(pkgBin, projPath), ".test"), "/")
 }
+
+// GenGoTestRunPatterns 根据标准go测试的用例名生成-test.run参数，每一层名称都使用锚定的正则精确匹配
+// 整体选中的顶层测试合并为一个正则；-test.run按`/`逐层匹配子测试，无法在同一个正则中表达不同层级的组合，因此每个子测试单独生成一个正则
+// 父测试已经选中时，其子测试不再单独执行
+func GenGoTestRunPatterns(tcNames []string) []string {
+	var topNames []string
+	selected := map[string]bool{}
+	for _, name := range tcNames {
+		if !strings.Contains(name, "/") && !selected[name] {
+			selected[name] = true
+			topNames = append(topNames, regexp.QuoteMeta(name))
+		}
+	}
+	var patterns []string
+	if len(topNames) > 0 {
+		patterns = append(patterns, fmt.Sprintf("^(%s)$", strings.Join(topNames, "|")))
+	}
+	for _, name := range tcNames {
+		levels := strings.Split(name, "/")
+		if len(levels) == 1 || parentSelected(selected, levels) {
+			continue
+		}
+		selected[name] = true
+		for i, level := range levels {
+			levels[i] = fmt.Sprintf("^%s$", regexp.QuoteMeta(level))
+		}
+		patterns = append(patterns, strings.Join(levels, "/"))
+	}
+	return patterns
+}
+
+// parentSelected 判断子测试本身或者任意一层父测试是否已经选中
+func parentSelected(selected map[string]bool, levels []string) bool {
+	for i := 1; i <= len(levels); i++ {
+		if selected[strings.Join(levels[:i], "/")] {
+			return true
+		}
+	}
+	return false
+}
+
+// ShellQuote 使用单引号包裹参数，避免参数中的正则元字符被shell解析
+func ShellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
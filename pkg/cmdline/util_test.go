@@ -35,3 +35,10 @@ func TestExtractPackPathFromBinFile(t *testing.T) {
 		})
 	}
 }
+
+func TestGenGoTestRunPatterns(t *testing.T) {
+	patterns := GenGoTestRunPatterns([]string{"TestA", "TestB/sub.one", "TestA/ignored", "TestC", "TestB/sub.one/deep", "TestD/x/y"})
+	assert.Equal(t, []string{`^(TestA|TestC)$`, `^TestB$/^sub\.one$`, `^TestD$/^x$/^y$`}, patterns)
+	assert.Len(t, GenGoTestRunPatterns(nil), 0)
+	assert.Equal(t, `'it'\''s'`, ShellQuote("it's"))
+}
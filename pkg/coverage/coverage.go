@@ -0,0 +1,261 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProfileFileName 合并后的覆盖率文件名，格式与`go test -coverprofile`一致
+	ProfileFileName = "coverage.out"
+	// SummaryFileName 覆盖率汇总文件名，记录每个包的语句数、覆盖语句数以及覆盖率
+	SummaryFileName = "summary.json"
+)
+
+// defaultCoverageDir 未指定覆盖率输出目录时使用的目录，相对于用例库根目录
+var defaultCoverageDir = filepath.Join(".testtool", "coverage")
+
+// PackageCoverage 单个包的覆盖率
+type PackageCoverage struct {
+	PackagePath string `json:"packagePath"`
+	// Profile 该包的覆盖率文件
+	Profile    string  `json:"profile"`
+	Statements int     `json:"statements"`
+	Covered    int     `json:"covered"`
+	Percent    float64 `json:"percent"`
+}
+
+// Summary 一次执行的覆盖率汇总
+type Summary struct {
+	// Profile 所有包合并后的覆盖率文件
+	Profile    string             `json:"profile"`
+	Statements int                `json:"statements"`
+	Covered    int                `json:"covered"`
+	Percent    float64            `json:"percent"`
+	Packages   []*PackageCoverage `json:"packages"`
+}
+
+// Enabled 通过环境变量TESTSOLAR_TTP_COVERAGE控制是否统计覆盖率
+func Enabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("TESTSOLAR_TTP_COVERAGE"))
+	return enabled
+}
+
+// Dir 覆盖率输出目录，通过环境变量TESTSOLAR_TTP_COVERAGEDIR指定，相对路径相对于用例库根目录
+func Dir(projPath string) string {
+	coverageDir := os.Getenv("TESTSOLAR_TTP_COVERAGEDIR")
+	if coverageDir == "" {
+		return filepath.Join(projPath, defaultCoverageDir)
+	}
+	if !filepath.IsAbs(coverageDir) {
+		coverageDir = filepath.Join(projPath, coverageDir)
+	}
+	return coverageDir
+}
+
+// packageKey 包在覆盖率目录中的文件名，避免嵌套的包路径互相包含
+func packageKey(packagePath string) string {
+	sum := sha256.Sum256([]byte(filepath.ToSlash(filepath.Clean(packagePath))))
+	return hex.EncodeToString(sum[:8])
+}
+
+// CounterDir 返回包的覆盖率数据目录，执行该包的用例时作为GOCOVERDIR
+func CounterDir(projPath, packagePath string) string {
+	return filepath.Join(Dir(projPath), "counters", packageKey(packagePath))
+}
+
+// packageProfile 返回包的覆盖率文件路径
+func packageProfile(projPath, packagePath string) string {
+	return filepath.Join(Dir(projPath), "profiles", packageKey(packagePath)+".out")
+}
+
+// Reset 清空上一次执行的覆盖率数据，每次执行的覆盖率单独统计
+func Reset(projPath string) error {
+	coverageDir := Dir(projPath)
+	for _, name := range []string{"counters", "profiles", ProfileFileName, SummaryFileName} {
+		if err := os.RemoveAll(filepath.Join(coverageDir, name)); err != nil {
+			return errors.Wrapf(err, "failed to clear coverage data in %s", coverageDir)
+		}
+	}
+	return nil
+}
+
+// PreparePackage 创建包的覆盖率数据目录并返回
+func PreparePackage(projPath, packagePath string) (string, error) {
+	counterDir := CounterDir(projPath, packagePath)
+	if err := os.MkdirAll(counterDir, 0755); err != nil {
+		return "", errors.Wrapf(err, "failed to create coverage dir %s", counterDir)
+	}
+	return counterDir, nil
+}
+
+// ReportPackage 将包的覆盖率数据转换为覆盖率文件并计算覆盖率
+func ReportPackage(projPath, packagePath string) (*PackageCoverage, error) {
+	profile := packageProfile(projPath, packagePath)
+	if err := textfmt(projPath, []string{CounterDir(projPath, packagePath)}, profile); err != nil {
+		return nil, err
+	}
+	statements, covered, err := parseProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return &PackageCoverage{
+		PackagePath: packagePath,
+		Profile:     profile,
+		Statements:  statements,
+		Covered:     covered,
+		Percent:     percent(statements, covered),
+	}, nil
+}
+
+// Merge 合并所有包的覆盖率数据，生成coverage.out以及覆盖率汇总summary.json
+func Merge(projPath string, packages []*PackageCoverage) (*Summary, error) {
+	coverageDir := Dir(projPath)
+	summary := &Summary{
+		Profile:  filepath.Join(coverageDir, ProfileFileName),
+		Packages: packages,
+	}
+	sort.Slice(summary.Packages, func(i, j int) bool {
+		return summary.Packages[i].PackagePath < summary.Packages[j].PackagePath
+	})
+	var counterDirs []string
+	for _, pkg := range packages {
+		counterDirs = append(counterDirs, CounterDir(projPath, pkg.PackagePath))
+	}
+	if err := textfmt(projPath, counterDirs, summary.Profile); err != nil {
+		return nil, err
+	}
+	statements, covered, err := parseProfile(summary.Profile)
+	if err != nil {
+		return nil, err
+	}
+	summary.Statements = statements
+	summary.Covered = covered
+	summary.Percent = percent(statements, covered)
+	content, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal coverage summary")
+	}
+	summaryFile := filepath.Join(coverageDir, SummaryFileName)
+	if err := os.WriteFile(summaryFile, content, 0644); err != nil {
+		return nil, errors.Wrapf(err, "failed to write coverage summary %s", summaryFile)
+	}
+	return summary, nil
+}
+
+// Log 在日志中打印每个包的覆盖率
+func (s *Summary) Log() {
+	log.Printf("Coverage summary, profile: %s", s.Profile)
+	for _, pkg := range s.Packages {
+		log.Printf("  %-50s %6.1f%% (%d/%d statements)", displayPackage(pkg.PackagePath), pkg.Percent, pkg.Covered, pkg.Statements)
+	}
+	log.Printf("  %-50s %6.1f%% (%d/%d statements)", "total", s.Percent, s.Covered, s.Statements)
+}
+
+func displayPackage(packagePath string) string {
+	if packagePath == "" {
+		return "."
+	}
+	return packagePath
+}
+
+// FormatPercent 格式化覆盖率，用于用例属性
+func FormatPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', 1, 64)
+}
+
+func percent(statements, covered int) float64 {
+	if statements == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(statements)
+}
+
+// textfmt 通过`go tool covdata textfmt`将覆盖率数据目录转换为文本格式的覆盖率文件，多个目录中的数据会合并
+// 包内没有可统计的语句时不会生成覆盖率数据，此时生成只包含mode的空覆盖率文件
+func textfmt(projPath string, counterDirs []string, profile string) error {
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create coverage dir %s", filepath.Dir(profile))
+	}
+	var inputs []string
+	for _, dir := range counterDirs {
+		if metaFiles, _ := filepath.Glob(filepath.Join(dir, "covmeta.*")); len(metaFiles) > 0 {
+			inputs = append(inputs, dir)
+		}
+	}
+	if len(inputs) == 0 {
+		return os.WriteFile(profile, []byte("mode: set\n"), 0644)
+	}
+	cmd := exec.Command("go", "tool", "covdata", "textfmt", "-i="+strings.Join(inputs, ","), "-o="+profile)
+	cmd.Dir = projPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "go tool covdata textfmt failed, stderr: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// parseProfile 统计覆盖率文件中的语句数以及被执行过的语句数
+// 覆盖率文件每行的格式为`file:startLine.startCol,endLine.endCol numStmt count`
+func parseProfile(profile string) (int, int, error) {
+	f, err := os.Open(profile)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to open coverage profile %s", profile)
+	}
+	defer f.Close()
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]*block{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, 0, fmt.Errorf("invalid line in coverage profile %s: %s", profile, line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid statements in coverage profile %s: %s", profile, line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid count in coverage profile %s: %s", profile, line)
+		}
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to read coverage profile %s", profile)
+	}
+	var statements, covered int
+	for _, b := range blocks {
+		statements += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	return statements, covered, nil
+}
@@ -0,0 +1,90 @@
+package coverage
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, projPath, name, content string) {
+	path := filepath.Join(projPath, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestParseProfile(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile(t, projPath, "coverage.out", `mode: set
+example.com/app/app.go:3.24,4.11 1 1
+example.com/app/app.go:4.11,6.3 1 0
+example.com/app/app.go:7.2,7.10 2 0
+example.com/app/app.go:7.2,7.10 2 1
+`)
+	statements, covered, err := parseProfile(filepath.Join(projPath, "coverage.out"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, statements)
+	assert.Equal(t, 3, covered)
+	assert.Equal(t, 75.0, percent(statements, covered))
+	assert.Equal(t, 0.0, percent(0, 0))
+	assert.Equal(t, "66.7", FormatPercent(200.0/3))
+
+	writeFile(t, projPath, "invalid.out", "mode: set\nexample.com/app/app.go:3.24,4.11 1\n")
+	_, _, err = parseProfile(filepath.Join(projPath, "invalid.out"))
+	assert.Error(t, err)
+}
+
+func TestCollectCoverage(t *testing.T) {
+	projPath := t.TempDir()
+	writeFile(t, projPath, "go.mod", "module example.com/cover\n\ngo 1.19\n")
+	writeFile(t, projPath, "app/app.go", "package app\n\nfunc Add(a, b int) int {\n\tif a > 0 {\n\t\treturn a + b\n\t}\n\treturn b\n}\n")
+	writeFile(t, projPath, "app/app_test.go", "package app\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) { Add(1, 2) }\n\nfunc TestZero(t *testing.T) { Add(0, 2) }\n")
+	writeFile(t, projPath, "empty/empty_test.go", "package empty\n\nimport \"testing\"\n\nfunc TestEmpty(t *testing.T) {}\n")
+	pkgBin := filepath.Join(projPath, "app.test")
+	build := exec.Command("go", "test", "-c", "-cover", "./app", "-o", pkgBin)
+	build.Dir = projPath
+	output, err := build.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	t.Setenv("TESTSOLAR_TTP_COVERAGEDIR", "out")
+	assert.Equal(t, filepath.Join(projPath, "out"), Dir(projPath))
+	counterDir, err := PreparePackage(projPath, "app")
+	require.NoError(t, err)
+	assert.DirExists(t, counterDir)
+	run := exec.Command(pkgBin, "-test.run", "^TestAdd$", "-test.gocoverdir="+counterDir)
+	output, err = run.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	appCoverage, err := ReportPackage(projPath, "app")
+	require.NoError(t, err)
+	assert.Equal(t, 3, appCoverage.Statements)
+	assert.Equal(t, 2, appCoverage.Covered)
+	assert.FileExists(t, appCoverage.Profile)
+	// 没有可统计语句的包生成空的覆盖率文件
+	_, err = PreparePackage(projPath, "empty")
+	require.NoError(t, err)
+	emptyCoverage, err := ReportPackage(projPath, "empty")
+	require.NoError(t, err)
+	assert.Equal(t, 0, emptyCoverage.Statements)
+
+	summary, err := Merge(projPath, []*PackageCoverage{emptyCoverage, appCoverage})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(projPath, "out", ProfileFileName), summary.Profile)
+	assert.Equal(t, 3, summary.Statements)
+	assert.Equal(t, 2, summary.Covered)
+	assert.Equal(t, []*PackageCoverage{appCoverage, emptyCoverage}, summary.Packages)
+	content, err := os.ReadFile(filepath.Join(projPath, "out", SummaryFileName))
+	require.NoError(t, err)
+	var saved Summary
+	require.NoError(t, json.Unmarshal(content, &saved))
+	assert.Equal(t, summary.Percent, saved.Percent)
+	assert.Len(t, saved.Packages, 2)
+
+	require.NoError(t, Reset(projPath))
+	assert.NoDirExists(t, counterDir)
+	assert.NoFileExists(t, summary.Profile)
+}
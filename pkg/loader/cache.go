@@ -0,0 +1,217 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ginkgoBuilder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	"github.com/pkg/errors"
+)
+
+// loadCacheVersion 缓存格式或者用例属性发生变化时需要更新该版本号，使旧的缓存失效
+const loadCacheVersion = "3"
+
+// LoadCacheDir 用例加载缓存所在目录，相对于用例库根目录
+var LoadCacheDir = filepath.Join(".testtool", "cache")
+
+// 影响加载结果的环境变量，其值会参与缓存键的计算
+var loadCacheEnvs = []string{"GOFLAGS", "TESTSOLAR_TTP_WITHOUTLABELS"}
+
+// loadCacheEntry 缓存文件内容，每个包对应一个缓存文件
+type loadCacheEntry struct {
+	Key string `json:"key"`
+	// SourceKey 由计算Key时以文件内容参与计算的本地文件计算，SourceFiles为这些文件的路径，位于用例库中的文件为相对路径
+	SourceKey   string                     `json:"sourceKey"`
+	SourceFiles []string                   `json:"sourceFiles"`
+	PackagePath string                     `json:"packagePath"`
+	TestCases   []*ginkgoTestcase.TestCase `json:"testcases"`
+}
+
+// loadCacheEnabled 通过环境变量TESTSOLAR_TTP_LOADCACHE控制是否使用缓存
+// 默认开启，设置为false时既不读取也不写入缓存，设置为clear时会在加载前清空缓存
+func loadCacheEnabled() bool {
+	return os.Getenv("TESTSOLAR_TTP_LOADCACHE") != "false"
+}
+
+// ClearLoadCacheIfRequired 当TESTSOLAR_TTP_LOADCACHE设置为clear时清空用例库下的加载缓存
+func ClearLoadCacheIfRequired(projPath string) error {
+	if os.Getenv("TESTSOLAR_TTP_LOADCACHE") != "clear" {
+		return nil
+	}
+	cacheDir := filepath.Join(projPath, LoadCacheDir)
+	log.Printf("Clear load cache in %s", cacheDir)
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return errors.Wrapf(err, "failed to clear load cache %s", cacheDir)
+	}
+	return nil
+}
+
+func loadCacheFile(projPath, packagePath string) string {
+	sum := sha256.Sum256([]byte(filepath.ToSlash(filepath.Clean(packagePath))))
+	return filepath.Join(projPath, LoadCacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// writeLoadCacheHeader 写入缓存键中与包的源码无关的部分，包括缓存版本、ginkgo版本、影响加载结果的环境变量以及编译参数
+func writeLoadCacheHeader(w io.Writer, ginkgoVersion int, buildFlags []string) {
+	fmt.Fprintf(w, "version:%s\nginkgo:%d\n", loadCacheVersion, ginkgoVersion)
+	for _, env := range loadCacheEnvs {
+		fmt.Fprintf(w, "env:%s=%s\n", env, os.Getenv(env))
+	}
+	fmt.Fprintf(w, "flags:%s\n", strings.Join(buildFlags, " "))
+}
+
+// genLoadCacheKey 根据包的编译指纹、影响加载结果的环境变量以及ginkgo版本计算缓存键，返回不包含用例的缓存记录
+// 编译指纹覆盖`go list -deps -test`列出的所有依赖，用例由用例库中其他包的函数生成时，修改这些包同样会使缓存失效
+// 编译参数中的-tags等参数会改变参与编译的文件，同样参与指纹的计算
+// 计算编译指纹需要执行`go env`以及`go list`，因此同时记录参与计算的本地文件，供下次读取缓存时快速校验
+func genLoadCacheKey(projPath, packagePath string, ginkgoVersion int) (*loadCacheEntry, error) {
+	buildFlags, err := ginkgoBuilder.PackageBuildFlags(projPath, packagePath)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, sources, err := ginkgoBuilder.GenFingerprintWithSources(projPath, packagePath, buildFlags)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	writeLoadCacheHeader(h, ginkgoVersion, buildFlags)
+	fmt.Fprintf(h, "fingerprint:%s\n", fingerprint)
+	entry := &loadCacheEntry{Key: hex.EncodeToString(h.Sum(nil)), PackagePath: packagePath}
+	for _, source := range sources {
+		if relPath, err := filepath.Rel(projPath, source); err == nil && !strings.HasPrefix(relPath, "..") {
+			source = relPath
+		}
+		entry.SourceFiles = append(entry.SourceFiles, source)
+	}
+	if entry.SourceKey, err = genLoadCacheSourceKey(projPath, packagePath, ginkgoVersion, entry.SourceFiles); err != nil {
+		log.Printf("generate load cache source key of %s failed, err: %v", packagePath, err)
+	}
+	return entry, nil
+}
+
+// genLoadCacheSourceKey 根据上次计算编译指纹时参与计算的本地文件计算缓存键，不需要执行go命令
+// 除文件内容外，包目录以及这些文件所在目录下的go文件列表同样参与计算，以便识别新增或者删除的go文件
+// go版本等go环境的变化以及依赖目录下新增的非go文件无法识别，需要通过TESTSOLAR_TTP_LOADCACHE=clear清空缓存
+func genLoadCacheSourceKey(projPath, packagePath string, ginkgoVersion int, sourceFiles []string) (string, error) {
+	buildFlags, err := ginkgoBuilder.PackageBuildFlags(projPath, packagePath)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	writeLoadCacheHeader(h, ginkgoVersion, buildFlags)
+	dirs := map[string]bool{packagePath: true}
+	for _, file := range sourceFiles {
+		if strings.HasSuffix(file, ".go") {
+			dirs[filepath.Dir(file)] = true
+		}
+		content, err := os.ReadFile(absCachePath(projPath, file))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read %s", file)
+		}
+		fmt.Fprintf(h, "file:%s\n", filepath.ToSlash(file))
+		h.Write(content)
+	}
+	var sortedDirs []string
+	for dir := range dirs {
+		sortedDirs = append(sortedDirs, dir)
+	}
+	sort.Strings(sortedDirs)
+	for _, dir := range sortedDirs {
+		entries, err := os.ReadDir(absCachePath(projPath, dir))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read %s", dir)
+		}
+		fmt.Fprintf(h, "dir:%s\n", filepath.ToSlash(dir))
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") {
+				fmt.Fprintf(h, "%s\n", e.Name())
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// absCachePath 返回缓存中记录的路径对应的绝对路径，位于用例库中的文件记录为相对于用例库根目录的路径
+func absCachePath(projPath, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projPath, path)
+}
+
+// readLoadCacheEntry 读取包对应的缓存文件，缓存不存在或者不属于该包时返回nil
+func readLoadCacheEntry(projPath, packagePath string) *loadCacheEntry {
+	content, err := os.ReadFile(loadCacheFile(projPath, packagePath))
+	if err != nil {
+		return nil
+	}
+	var entry loadCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		log.Printf("unmarshal load cache of %s failed, err: %v", packagePath, err)
+		return nil
+	}
+	if entry.PackagePath != packagePath {
+		return nil
+	}
+	return &entry
+}
+
+// readLoadCache 读取包对应的缓存，命中时返回缓存的用例以及true
+// 先根据缓存中记录的本地文件校验，文件均未变化时直接命中，不需要执行`go env`以及`go list`
+// 否则重新计算编译指纹，指纹未变化时同样命中并更新记录的文件；未命中时返回用于写入缓存的记录，计算指纹失败时不写入缓存
+func readLoadCache(projPath, packagePath string, ginkgoVersion int) ([]*ginkgoTestcase.TestCase, *loadCacheEntry, bool) {
+	cached := readLoadCacheEntry(projPath, packagePath)
+	if cached != nil && cached.SourceKey != "" {
+		sourceKey, err := genLoadCacheSourceKey(projPath, packagePath, ginkgoVersion, cached.SourceFiles)
+		if err == nil && sourceKey == cached.SourceKey {
+			return cached.TestCases, nil, true
+		}
+	}
+	entry, err := genLoadCacheKey(projPath, packagePath, ginkgoVersion)
+	if err != nil {
+		log.Printf("generate load cache key of %s failed, err: %v", packagePath, err)
+		return nil, nil, false
+	}
+	if cached != nil && cached.Key == entry.Key {
+		if err := writeLoadCache(projPath, entry, cached.TestCases); err != nil {
+			log.Printf("write load cache of %s failed, err: %v", packagePath, err)
+		}
+		return cached.TestCases, nil, true
+	}
+	return nil, entry, false
+}
+
+// writeLoadCache 按genLoadCacheKey返回的记录写入包对应的缓存，先写入临时文件再重命名，避免并发读取到不完整的内容
+func writeLoadCache(projPath string, entry *loadCacheEntry, testcases []*ginkgoTestcase.TestCase) error {
+	packagePath := entry.PackagePath
+	cacheFile := loadCacheFile(projPath, packagePath)
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create load cache dir")
+	}
+	written := *entry
+	written.TestCases = testcases
+	content, err := json.Marshal(&written)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal load cache of %s", packagePath)
+	}
+	tmpFile := cacheFile + "." + ginkgoUtil.GenRandomString(8) + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write load cache %s", tmpFile)
+	}
+	if err := os.Rename(tmpFile, cacheFile); err != nil {
+		_ = os.Remove(tmpFile)
+		return errors.Wrapf(err, "failed to write load cache %s", cacheFile)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ginkgoBuilder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCache(t *testing.T) {
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "go.mod", "module example.com/demo\n\ngo 1.19\n")
+	helperPath := writeTestFile(t, projPath, "helper/helper.go", "package helper\n\nfunc Name() string { return \"a\" }\n")
+	path := writeTestFile(t, projPath, "cache/cache_test.go", "package cache\n\nimport _ \"example.com/demo/helper\"\n")
+
+	entry, err := genLoadCacheKey(projPath, "cache", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache/cache_test.go", "go.mod", "helper/helper.go"}, entry.SourceFiles)
+	sameEntry, err := genLoadCacheKey(projPath, "cache", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, entry, sameEntry)
+	otherVersionEntry, err := genLoadCacheKey(projPath, "cache", 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, entry.Key, otherVersionEntry.Key)
+	assert.NotEqual(t, entry.SourceKey, otherVersionEntry.SourceKey)
+
+	_, missed, ok := readLoadCache(projPath, "cache", 2)
+	assert.False(t, ok)
+	assert.Equal(t, entry, missed)
+	testcases := []*ginkgoTestcase.TestCase{
+		{Path: "cache/cache_test.go", Name: "cached case", Attributes: map[string]string{"ginkgoVersion": "2"}},
+	}
+	err = writeLoadCache(projPath, entry, testcases)
+	assert.NoError(t, err)
+
+	// 记录的文件均未变化时直接命中缓存，不需要计算编译指纹
+	var fingerprintCount int
+	patches := gomonkey.ApplyFunc(ginkgoBuilder.GenFingerprintWithSources, func(projPath, packagePath string, buildFlags []string) (string, []string, error) {
+		fingerprintCount++
+		return "", nil, errors.New("unexpected fingerprint")
+	})
+	cached, _, ok := readLoadCache(projPath, "cache", 2)
+	patches.Reset()
+	assert.True(t, ok)
+	assert.Equal(t, testcases, cached)
+	assert.Equal(t, 0, fingerprintCount)
+
+	// 不参与编译的go文件只改变文件列表，编译指纹未变化时仍然命中缓存，并更新记录
+	writeTestFile(t, projPath, "cache/_ignored.go", "package cache\n")
+	cached, _, ok = readLoadCache(projPath, "cache", 2)
+	assert.True(t, ok)
+	assert.Equal(t, testcases, cached)
+	refreshed := readLoadCacheEntry(projPath, "cache")
+	assert.Equal(t, entry.Key, refreshed.Key)
+	assert.NotEqual(t, entry.SourceKey, refreshed.SourceKey)
+
+	// 包内文件发生变化后缓存失效
+	err = os.WriteFile(path, []byte("package cache\n\nimport _ \"example.com/demo/helper\"\n\n// changed\n"), 0644)
+	assert.NoError(t, err)
+	_, fileChangedEntry, ok := readLoadCache(projPath, "cache", 2)
+	assert.False(t, ok)
+	assert.NotEqual(t, entry.Key, fileChangedEntry.Key)
+	assert.NoError(t, writeLoadCache(projPath, fileChangedEntry, testcases))
+	// 用例库中依赖的包发生变化后缓存同样失效
+	err = os.WriteFile(helperPath, []byte("package helper\n\nfunc Name() string { return \"b\" }\n"), 0644)
+	assert.NoError(t, err)
+	_, changedEntry, ok := readLoadCache(projPath, "cache", 2)
+	assert.False(t, ok)
+	assert.NotEqual(t, fileChangedEntry.Key, changedEntry.Key)
+	// 依赖的包中新增go文件时缓存同样失效
+	assert.NoError(t, writeLoadCache(projPath, changedEntry, testcases))
+	writeTestFile(t, projPath, "helper/extra.go", "package helper\n\nfunc Extra() string { return \"c\" }\n")
+	_, _, ok = readLoadCache(projPath, "cache", 2)
+	assert.False(t, ok)
+
+	// 命中缓存时不需要编译用例包
+	hitEntry, err := genLoadCacheKey(projPath, "cache", ginkgoUtil.FindGinkgoVersion(filepath.Join(projPath, "cache")))
+	assert.NoError(t, err)
+	err = writeLoadCache(projPath, hitEntry, testcases)
+	assert.NoError(t, err)
+	loaded, loadErrors := dynamicLoadTestcase(context.Background(), projPath, "cache")
+	assert.Len(t, loadErrors, 0)
+	assert.Equal(t, testcases, loaded)
+
+	defer os.Setenv("TESTSOLAR_TTP_LOADCACHE", os.Getenv("TESTSOLAR_TTP_LOADCACHE"))
+	err = os.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	assert.NoError(t, err)
+	assert.False(t, loadCacheEnabled())
+	err = ClearLoadCacheIfRequired(projPath)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(projPath, LoadCacheDir))
+	assert.NoError(t, err)
+
+	err = os.Setenv("TESTSOLAR_TTP_LOADCACHE", "clear")
+	assert.NoError(t, err)
+	assert.True(t, loadCacheEnabled())
+	err = ClearLoadCacheIfRequired(projPath)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(projPath, LoadCacheDir))
+	assert.True(t, os.IsNotExist(err))
+}
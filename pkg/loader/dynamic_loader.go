@@ -1,13 +1,17 @@
 package loader
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	ginkgoBuilder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
 	ginkgoResult "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/result"
@@ -16,40 +20,64 @@ import (
 
 	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/conc/pool"
 )
 
-func findBinFile(absSelectorPath string) string {
-	testFileName := absSelectorPath + ".test"
-	_, err := os.Stat(testFileName)
-	if os.IsNotExist(err) {
+// defaultLoadTimeout 单个包动态加载的默认超时时间
+const defaultLoadTimeout = 10 * time.Minute
+
+// findBinFile 查找包已编译的二进制文件，优先使用编译清单中的记录，返回绝对路径
+func findBinFile(projPath string, packagePath string) string {
+	pkgBin := ginkgoBuilder.ResolveBinary(projPath, packagePath)
+	if pkgBin == "" {
 		return ""
 	}
-	absPath, err := filepath.Abs(testFileName)
+	absPath, err := filepath.Abs(pkgBin)
 	if err != nil {
 		return ""
 	}
 	return absPath
 }
 
-func ginkgo_v1_load(projPath, pkgBin string) ([]*ginkgoTestcase.TestCase, error) {
-	var caseList []*ginkgoTestcase.TestCase
+var junitMessageRegex = regexp.MustCompile(`(?m)^JUnit (path was configured|report was created): .*\n?`)
+
+func ginkgo_v1_load(ctx context.Context, projPath, path, pkgBin string) ([]*ginkgoTestcase.TestCase, error) {
 	var cmdline string
-	workDir := strings.TrimSuffix(pkgBin, ".test")
+	workDir := filepath.Join(projPath, path)
+	reportDir, cleanup, err := ginkgoUtil.NewReportDir(projPath, "dryrun-"+path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	reportXml := filepath.Join(reportDir, "junit.xml")
 	if pkgBin != "" {
-		cmdline = strings.Join([]string{pkgBin, "--ginkgo.v --ginkgo.dryRun --ginkgo.noColor"}, " ")
+		cmdline = strings.Join([]string{pkgBin, "--ginkgo.v --ginkgo.dryRun --ginkgo.noColor --ginkgo.reportFile=" + reportXml}, " ")
 	} else {
 		if _, err := exec.LookPath("ginkgo"); err != nil {
 			return nil, errors.Wrapf(err, "there is no test and ginkgo binary")
 		}
-		cmdline = "ginkgo --v --dry-run --no-color ."
+		cmdline = "ginkgo --v --dry-run --no-color --reportFile=" + reportXml + " ."
 	}
 	log.Printf("dry run cmd: %s in dir: %s", cmdline, workDir)
-	stdout, stderr, err := ginkgoUtil.RunCommandWithOutput(cmdline, workDir)
+	stdout, stderr, err := ginkgoUtil.RunCommandWithContextAndOutput(ctx, cmdline, workDir, nil)
 	if err != nil {
 		message := fmt.Sprintf("dry run command failed, cmdline: %s, err: %v, stdout: %s, stderr: %s", cmdline, err, stdout, stderr)
 		log.Println(message)
 		return nil, errors.New(message)
 	}
+	// 优先使用JUnit报告中的用例名，只有测试套使用自定义reporter等原因导致报告不存在时才从标准输出中解析用例
+	if exists, err := ginkgoUtil.FileExists(reportXml); err == nil && exists {
+		junitCases, err := ginkgoResult.ParseJUnitCases(reportXml)
+		if err == nil {
+			log.Printf("Parse junit report %s, found %d testcases", reportXml, len(junitCases))
+			return genV1TestCases(projPath, path, junitCases, stdout), nil
+		}
+		log.Printf("parse junit report %s failed, err: %v, try to parse cases by stdout", reportXml, err)
+	} else {
+		log.Printf("dry run junit report not exists, try to parse cases by stdout")
+	}
+	// 去除指定reportFile后JUnit reporter输出的提示信息，避免被当作用例名的一部分
+	stdout = junitMessageRegex.ReplaceAllString(stdout, "")
 	// 如果加载出来测试套中的用例数为空则直接返回
 	if strings.Contains(stdout, "Ran 0 of 0 Specs in 0.000 seconds") {
 		log.Printf("no testcases found in %s", pkgBin)
@@ -64,30 +92,75 @@ func ginkgo_v1_load(projPath, pkgBin string) ([]*ginkgoTestcase.TestCase, error)
 	if len(testcaseList) == 0 {
 		return nil, fmt.Errorf("failed to find testcases from stdout, stdout: %s, stderr: %s", stdout, stderr)
 	}
-	caseList = append(caseList, testcaseList...)
-	return caseList, nil
+	return testcaseList, nil
+}
+
+// genV1TestCases 根据JUnit报告中的用例名生成用例，JUnit报告中不包含用例位置
+// 因此通过静态解析包内源码获取用例所在文件以及定义位置，静态解析无法识别的用例再从dry run的标准输出中查找
+// pending用例与静态解析保持一致，记录pending属性
+func genV1TestCases(projPath, path string, junitCases []*ginkgoResult.JUnitCase, stdout string) []*ginkgoTestcase.TestCase {
+	locatedCases := map[string]*ginkgoTestcase.TestCase{}
+	staticCases, _ := ParseTestCaseInPackage(projPath, filepath.Join(projPath, path))
+	for _, c := range staticCases {
+		locatedCases[c.Name] = c
+	}
+	if regCases, err := ginkgoResult.ParseCaseByReg(projPath, stdout, 1, ""); err == nil {
+		for _, c := range regCases {
+			if _, ok := locatedCases[c.Name]; !ok {
+				locatedCases[c.Name] = c
+			}
+		}
+	}
+	var caseList []*ginkgoTestcase.TestCase
+	for _, junitCase := range junitCases {
+		name := junitCase.Name
+		caseInfo := &ginkgoTestcase.TestCase{
+			Path:       path,
+			Name:       name,
+			Attributes: map[string]string{},
+		}
+		if located, ok := locatedCases[name]; ok {
+			caseInfo.Path = located.Path
+			for _, key := range ginkgoResult.LocationAttributeKeys {
+				if value, ok := located.Attributes[key]; ok {
+					caseInfo.Attributes[key] = value
+				}
+			}
+		} else {
+			log.Printf("can't find location of testcase %s in %s", name, path)
+		}
+		if junitCase.Pending {
+			caseInfo.Attributes["pending"] = "true"
+		}
+		caseInfo.Attributes["ginkgoVersion"] = strconv.Itoa(1)
+		caseList = append(caseList, caseInfo)
+	}
+	return caseList
 }
 
-func ginkgo_v2_load(projPath, path, pkgBin string) ([]*ginkgoTestcase.TestCase, error) {
+func ginkgo_v2_load(ctx context.Context, projPath, path, pkgBin string) ([]*ginkgoTestcase.TestCase, error) {
 	var caseList []*ginkgoTestcase.TestCase
 	var cmdline string
 	var workDir string
+	// 多个包可能同时加载，因此每次dry run的报告都写入独立的目录
+	reportDir, cleanup, err := ginkgoUtil.NewReportDir(projPath, "dryrun-"+path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	reportJson := filepath.Join(reportDir, "report.json")
 	if pkgBin != "" {
-		cmdline = strings.Join([]string{pkgBin, "--ginkgo.v --ginkgo.dry-run --ginkgo.no-color --ginkgo.json-report=report.json"}, " ")
+		cmdline = strings.Join([]string{pkgBin, fmt.Sprintf("--ginkgo.v --ginkgo.dry-run --ginkgo.no-color --ginkgo.json-report=%s", reportJson)}, " ")
 		workDir = projPath
 	} else {
 		if _, err := exec.LookPath("ginkgo"); err != nil {
 			return nil, errors.Wrapf(err, "there is no test and ginkgo binary")
 		}
-		cmdline = "ginkgo --v --dry-run --no-color --json-report=report.json ."
+		cmdline = fmt.Sprintf("ginkgo --v --dry-run --no-color --json-report=report.json --output-dir=%s .", reportDir)
 		workDir = filepath.Join(projPath, path)
 	}
-	reportJson := filepath.Join(workDir, "report.json")
-	if err := os.Remove(reportJson); err != nil {
-		log.Printf("remove report json file %s failed, err: %v", reportJson, err)
-	}
 	log.Printf("dry run cmd: %s\nwork directory: %s", cmdline, workDir)
-	stdout, stderr, err := ginkgoUtil.RunCommandWithOutput(cmdline, workDir)
+	stdout, stderr, err := ginkgoUtil.RunCommandWithContextAndOutput(ctx, cmdline, workDir, nil)
 	if err != nil {
 		return nil, fmt.Errorf("dry run command %s failed, err: %v", cmdline, err)
 	}
@@ -129,33 +202,53 @@ func ginkgo_v2_load(projPath, path, pkgBin string) ([]*ginkgoTestcase.TestCase,
 	return caseList, nil
 }
 
-func dynamicLoadTestcase(projPath string, selectorPath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+// buildLoadErrors 将编译失败转换为LoadError，能够定位到文件行的编译错误各自上报为一条以`file:line`命名的LoadError
+func buildLoadErrors(packagePath string, message string, err error) []*sdkModel.LoadError {
+	var buildErr *ginkgoBuilder.BuildError
+	if !errors.As(err, &buildErr) || len(buildErr.Diagnostics) == 0 {
+		return []*sdkModel.LoadError{{Name: packagePath, Message: message}}
+	}
+	var loadErrors []*sdkModel.LoadError
+	for _, d := range buildErr.Diagnostics {
+		loadErrors = append(loadErrors, &sdkModel.LoadError{
+			Name:    d.Position(),
+			Message: d.Message,
+		})
+	}
+	return loadErrors
+}
+
+func dynamicLoadTestcase(ctx context.Context, projPath string, selectorPath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
 	var caseList []*ginkgoTestcase.TestCase
 	absSelectorPath := filepath.Join(projPath, selectorPath)
-	pkgBin := findBinFile(absSelectorPath)
+	ginkgoVersion := ginkgoUtil.FindGinkgoVersion(absSelectorPath)
+	var cacheEntry *loadCacheEntry
+	if loadCacheEnabled() {
+		cached, entry, ok := readLoadCache(projPath, selectorPath, ginkgoVersion)
+		if ok {
+			log.Printf("load %d testcases of %s from cache", len(cached), selectorPath)
+			return cached, nil
+		}
+		cacheEntry = entry
+	}
+	pkgBin := findBinFile(projPath, selectorPath)
 	if pkgBin == "" {
 		log.Printf("Can't find package bin file %s during loading, try to build it...", pkgBin)
 		var err error
-		pkgBin, err = ginkgoBuilder.BuildTestPackage(projPath, selectorPath, false)
+		pkgBin, err = ginkgoBuilder.BuildTestPackageWithContext(ctx, projPath, selectorPath, false)
 		if err != nil {
 			message := fmt.Sprintf("Build package %s during loading failed, err: %s", selectorPath, err.Error())
 			log.Println(message)
-			return nil, []*sdkModel.LoadError{
-				{
-					Name:    selectorPath,
-					Message: message,
-				},
-			}
+			return nil, buildLoadErrors(selectorPath, message, err)
 		}
 	}
-	ginkgoVersion := ginkgoUtil.FindGinkgoVersion(absSelectorPath)
 	log.Printf("load testcase by bin file %s under ginkgo %d", pkgBin, ginkgoVersion)
 	var err error
 	var testcaseList []*ginkgoTestcase.TestCase
 	if ginkgoVersion == 2 {
-		testcaseList, err = ginkgo_v2_load(projPath, selectorPath, pkgBin)
+		testcaseList, err = ginkgo_v2_load(ctx, projPath, selectorPath, pkgBin)
 	} else {
-		testcaseList, err = ginkgo_v1_load(projPath, pkgBin)
+		testcaseList, err = ginkgo_v1_load(ctx, projPath, selectorPath, pkgBin)
 	}
 	if err != nil {
 		message := fmt.Sprintf("load testcase by bin file %s from %s failed, err: %v", pkgBin, selectorPath, err)
@@ -172,45 +265,86 @@ func dynamicLoadTestcase(projPath string, selectorPath string) ([]*ginkgoTestcas
 		log.Println(testcase.GetSelector())
 	}
 	caseList = append(caseList, testcaseList...)
+	// 超时后不再写入缓存，避免被终止的加载产生的结果污染缓存
+	if cacheEntry != nil && ctx.Err() == nil {
+		if err := writeLoadCache(projPath, cacheEntry, caseList); err != nil {
+			log.Printf("write load cache of %s failed, err: %v", selectorPath, err)
+		}
+	}
 	return caseList, nil
 }
 
-func getAvailableSuitePath(projPath, rootPath string) ([]string, error) {
+// getAvailableSuitePath 查找rootPath下的测试套，返回相对于projPath的包路径
+// 包含ginkgo用例但没有调用RunSpecs的包无法编译执行，会以加载错误的形式返回
+func getAvailableSuitePath(projPath, rootPath string) ([]string, []*sdkModel.LoadError, error) {
+	suiteDirs, noEntryDirs, err := ginkgoUtil.FindSuitePackages(rootPath)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to find suite packages in %s", rootPath)
+	}
 	var packageList []string
-	err := filepath.Walk(rootPath, func(path string, fi os.FileInfo, e error) error {
-		if e != nil {
-			return errors.Wrapf(e, "failed to walk %s", path)
-		}
-		if !fi.IsDir() && strings.HasSuffix(path, "_suite_test.go") {
-			packagePath := filepath.Dir(path)
-			if packagePath == projPath {
-				packagePath = ""
+	for _, dir := range suiteDirs {
+		packageList = append(packageList, relPackagePath(projPath, dir))
+	}
+	var loadErrors []*sdkModel.LoadError
+	for _, dir := range noEntryDirs {
+		packagePath := relPackagePath(projPath, dir)
+		log.Printf("Package %s has ginkgo specs but no RunSpecs entrypoint", packagePath)
+		loadErrors = append(loadErrors, &sdkModel.LoadError{
+			Name:    packagePath,
+			Message: noEntryMessage(packagePath),
+		})
+	}
+	return packageList, loadErrors, nil
+}
+
+func noEntryMessage(packagePath string) string {
+	return fmt.Sprintf("package %s imports ginkgo but none of its test files calls RunSpecs, add a TestXxx(t *testing.T) function calling RunSpecs to run its specs", packagePath)
+}
+
+// relPackagePath 返回包目录相对于projPath的路径，包目录即为projPath时返回空字符串
+func relPackagePath(projPath, dir string) string {
+	relPath, err := filepath.Rel(projPath, dir)
+	if err != nil {
+		log.Printf("get rel path failed, basepath %s, targpath: %s, err: %v", projPath, dir, err)
+		return strings.TrimPrefix(strings.TrimPrefix(dir, projPath), "/")
+	}
+	if relPath == "." {
+		return ""
+	}
+	return relPath
+}
+
+// dynamicLoadPackage 动态加载单个包中的用例，packagePath为相对于projPath的包路径
+func dynamicLoadPackage(ctx context.Context, projPath string, packagePath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	log.Printf("Start dynamic load testcase from: %v", packagePath)
+	caseList, loadError := dynamicLoadTestcase(ctx, projPath, packagePath)
+	if loadError != nil {
+		log.Printf("dynamic load testcase from %s failed, load errors: %v", packagePath, loadError)
+		return nil, loadError
+	}
+	// 如果加载出来的用例实际路径与下发的包路径不一致，则表明该用例为共享用例（用例被其他路径下的用例所引用）
+	// 这种情况下无法确定用例具体对应的文件路径，因此需要将用例文件路径修改为包下调用RunSpecs的测试套入口文件
+	for _, c := range caseList {
+		if c.Path != packagePath && !strings.HasPrefix(c.Path, packagePath) {
+			suiteFileName, err := ginkgoUtil.GetSuiteFileNameInPackage(filepath.Join(projPath, packagePath))
+			if err != nil {
+				log.Printf("get suite file name in package %s failed, err: %v", packagePath, err)
+				log.Printf("Loaded case [path: %s, name: %s] has different path with package: %s, replace case's path to package path", c.Path, c.Name, packagePath)
+				c.Path = packagePath
 			} else {
-				if relPath, err := filepath.Rel(projPath, packagePath); err != nil {
-					log.Printf("get rel path failed, basepath %s, targpath: %s, err: %v", projPath, packagePath, err)
-					relPath = strings.TrimPrefix(packagePath, projPath)
-					relPath = strings.TrimPrefix(relPath, "/")
-					packagePath = relPath
-				} else {
-					packagePath = relPath
-				}
-			}
-			if !ginkgoUtil.ElementIsInSlice(packagePath, packageList) {
-				packageList = append(packageList, packagePath)
+				suitePath := filepath.Join(packagePath, suiteFileName)
+				log.Printf("Loaded case [path: %s, name: %s] has different path with package: %s, replace case's path to suite path %s", c.Path, c.Name, packagePath, suitePath)
+				c.Path = suitePath
 			}
 		}
-		return nil
-	})
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to walk %s", rootPath)
 	}
-	return packageList, nil
+	return caseList, nil
 }
 
 func DynamicLoadTestcaseInDir(projPath string, rootPath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
 	var testcaseList []*ginkgoTestcase.TestCase
 	var loadErrors []*sdkModel.LoadError
-	packageList, err := getAvailableSuitePath(projPath, rootPath)
+	packageList, lErrors, err := getAvailableSuitePath(projPath, rootPath)
 	if err != nil {
 		log.Printf("get available suite path of %s failed: %v", rootPath, err)
 		loadErrors = append(loadErrors, &sdkModel.LoadError{
@@ -219,36 +353,101 @@ func DynamicLoadTestcaseInDir(projPath string, rootPath string) ([]*ginkgoTestca
 		})
 		return nil, loadErrors
 	}
+	loadErrors = append(loadErrors, lErrors...)
 	log.Printf("Available package list: %v, root path: %s", packageList, rootPath)
-	for _, packagePath := range packageList {
-		log.Printf("Start dynamic load testcase from: %v", packagePath)
-		caseList, loadError := dynamicLoadTestcase(projPath, packagePath)
-		if loadError != nil {
-			log.Printf("dynamic load testcase from %s failed, load errors: %v", packagePath, loadError)
-			loadErrors = append(loadErrors, loadError...)
+	for _, result := range dynamicLoadPackages(projPath, packageList) {
+		if result.loadErrors != nil {
+			loadErrors = append(loadErrors, result.loadErrors...)
 			continue
 		}
-		// 如果加载出来的用例实际路径与下发的包路径不一致，则表明该用例为共享用例（用例被其他路径下的用例所引用）
-		// 这种情况下无法确定用例具体对应的文件路径，因此需要将用例文件路径修改为包下的_suite_test.go文件
-		for _, c := range caseList {
-			if c.Path != packagePath && !strings.HasPrefix(c.Path, packagePath) {
-				suiteFileName, err := ginkgoUtil.GetSuiteFileNameInPackage(packagePath)
-				if err != nil {
-					log.Printf("get suite file name in package %s failed, err: %v", packagePath, err)
-					log.Printf("Loaded case [path: %s, name: %s] has different path with package: %s, replace case's path to package path", c.Path, c.Name, packagePath)
-					c.Path = packagePath
-				} else {
-					suitePath := filepath.Join(packagePath, suiteFileName)
-					log.Printf("Loaded case [path: %s, name: %s] has different path with package: %s, replace case's path to suite path %s", c.Path, c.Name, packagePath, suitePath)
-					c.Path = suitePath
-				}
-			}
-		}
-		testcaseList = append(testcaseList, caseList...)
+		testcaseList = append(testcaseList, result.testcases...)
 	}
 	return testcaseList, loadErrors
 }
 
+// packageLoadResult 单个包的动态加载结果
+type packageLoadResult struct {
+	testcases  []*ginkgoTestcase.TestCase
+	loadErrors []*sdkModel.LoadError
+}
+
+// loadWorkerCount 动态加载的并发数，通过环境变量TESTSOLAR_TTP_WORKERCOUNT指定，未指定或者不大于0时使用CPU核数
+func loadWorkerCount() int {
+	if workerCount, err := strconv.Atoi(os.Getenv("TESTSOLAR_TTP_WORKERCOUNT")); err == nil && workerCount > 0 {
+		return workerCount
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// loadTimeout 单个包动态加载(包括编译和dry run)的超时时间，通过环境变量TESTSOLAR_TTP_LOADTIMEOUT指定，设置为0时不限制
+func loadTimeout() time.Duration {
+	value := os.Getenv("TESTSOLAR_TTP_LOADTIMEOUT")
+	if value == "" {
+		return defaultLoadTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid load timeout %s, use default value %s", value, defaultLoadTimeout)
+		return defaultLoadTimeout
+	}
+	return timeout
+}
+
+// packageLoader 加载单个包中用例的函数，packagePath为相对于projPath的包路径，ctx超时后需要终止加载过程中启动的进程
+type packageLoader func(ctx context.Context, projPath string, packagePath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError)
+
+// dynamicLoadPackages 使用有限数量的worker并发动态加载多个包，返回结果的顺序与packageList保持一致
+func dynamicLoadPackages(projPath string, packageList []string) []packageLoadResult {
+	return loadPackages(projPath, packageList, dynamicLoadPackage)
+}
+
+// loadPackages 使用有限数量的worker并发调用load加载多个包，返回结果的顺序与packageList保持一致
+func loadPackages(projPath string, packageList []string, load packageLoader) []packageLoadResult {
+	results := make([]packageLoadResult, len(packageList))
+	workerCount := loadWorkerCount()
+	timeout := loadTimeout()
+	log.Printf("Dynamic load %d packages with %d workers, timeout of each package: %s", len(packageList), workerCount, timeout)
+	p := pool.New().WithMaxGoroutines(workerCount)
+	for i, packagePath := range packageList {
+		i, packagePath := i, packagePath
+		p.Go(func() {
+			results[i] = loadPackageWithTimeout(projPath, packagePath, timeout, load)
+		})
+	}
+	p.Wait()
+	return results
+}
+
+// loadPackageWithTimeout 加载单个包，超时后取消ctx终止加载过程中启动的编译以及dry run进程，直接返回加载错误并释放worker，避免卡住的包阻塞其他包的加载
+func loadPackageWithTimeout(projPath string, packagePath string, timeout time.Duration, load packageLoader) packageLoadResult {
+	if timeout <= 0 {
+		caseList, loadErrors := load(context.Background(), projPath, packagePath)
+		return packageLoadResult{testcases: caseList, loadErrors: loadErrors}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	done := make(chan packageLoadResult, 1)
+	go func() {
+		caseList, loadErrors := load(ctx, projPath, packagePath)
+		done <- packageLoadResult{testcases: caseList, loadErrors: loadErrors}
+	}()
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		message := fmt.Sprintf("dynamic load package %s timeout after %s", packagePath, timeout)
+		log.Println(message)
+		return packageLoadResult{
+			loadErrors: []*sdkModel.LoadError{
+				{
+					Name:    packagePath,
+					Message: message,
+				},
+			},
+		}
+	}
+}
+
 func DynamicLoadTestcaseInFile(projPath string, filePath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
 	var loadErrors []*sdkModel.LoadError
 	selectorPath, err := filepath.Rel(projPath, filePath)
@@ -263,17 +462,43 @@ func DynamicLoadTestcaseInFile(projPath string, filePath string) ([]*ginkgoTestc
 	log.Printf("Start dynamic load testcase in file %s", selectorPath)
 	// 这里处理文件，扫描文件上一层的用例，然后过滤
 	parentDir := filepath.Dir(selectorPath)
-	testcaseList, loadErrors := dynamicLoadTestcase(projPath, parentDir)
-	for _, c := range testcaseList {
-		// 如果加载出来的用例路径不在当前包下，则表明该用例为一个引用用例
-		// 低版本ginkgo对于引用用例无法正确解析用例的引用路径，因此需要将用例路径设置为当前下发的加载文件路径
-		if !strings.HasPrefix(c.Path, parentDir) {
-			c.Path = selectorPath
+	if _, err := ginkgoUtil.FindSuiteFile(filepath.Join(projPath, parentDir)); err != nil {
+		// 不包含ginkgo用例的包中只有标准go测试，由go测试的加载流程处理
+		if importsGinkgo, _ := ginkgoUtil.PackageImportsGinkgo(filepath.Join(projPath, parentDir)); !importsGinkgo {
+			return nil, nil
+		}
+		log.Printf("find suite entrypoint of %s failed, err: %v", selectorPath, err)
+		return nil, []*sdkModel.LoadError{
+			{
+				Name:    selectorPath,
+				Message: noEntryMessage(parentDir),
+			},
 		}
 	}
+	testcaseList, loadErrors := dynamicLoadTestcase(context.Background(), projPath, parentDir)
 	if loadErrors != nil {
 		log.Printf("dynamic load testcase in %s failed: %v", selectorPath, loadErrors)
 		return nil, loadErrors
 	}
-	return testcaseList, nil
+	return rewriteSharedTestcases(testcaseList, parentDir, selectorPath), nil
+}
+
+// rewriteSharedTestcases 如果加载出来的用例路径不在当前包下，则表明该用例为一个引用用例
+// 低版本ginkgo对于引用用例无法正确解析用例的引用路径，因此需要将用例路径设置为当前下发的加载文件路径
+// 改写后的路径取决于下发的文件，因此改写的是用例的副本，同一个包的加载结果可以被包内其他文件复用
+func rewriteSharedTestcases(testcases []*ginkgoTestcase.TestCase, packagePath string, selectorPath string) []*ginkgoTestcase.TestCase {
+	rewritten := make([]*ginkgoTestcase.TestCase, 0, len(testcases))
+	for _, c := range testcases {
+		if !strings.HasPrefix(c.Path, packagePath) {
+			shared := *c
+			shared.Attributes = make(map[string]string, len(c.Attributes))
+			for k, v := range c.Attributes {
+				shared.Attributes[k] = v
+			}
+			shared.Path = selectorPath
+			c = &shared
+		}
+		rewritten = append(rewritten, c)
+	}
+	return rewritten
 }
@@ -0,0 +1,225 @@
+package loader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+	"github.com/agiledragon/gomonkey/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamicLoadPackages(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_WORKERCOUNT", "2")
+	t.Setenv("TESTSOLAR_TTP_LOADTIMEOUT", "500ms")
+	var running, maxRunning int32
+	dynamicLoadPackageMock := gomonkey.ApplyFunc(dynamicLoadPackage, func(ctx context.Context, projPath string, packagePath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&maxRunning)
+			if current <= old || atomic.CompareAndSwapInt32(&maxRunning, old, current) {
+				break
+			}
+		}
+		switch packagePath {
+		case "hang":
+			time.Sleep(5 * time.Second)
+		case "broken":
+			return nil, []*sdkModel.LoadError{{Name: packagePath, Message: "build failed"}}
+		case "slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+		return []*ginkgoTestcase.TestCase{{Path: packagePath + "/a_test.go", Name: packagePath}}, nil
+	})
+	defer dynamicLoadPackageMock.Reset()
+
+	start := time.Now()
+	results := dynamicLoadPackages("", []string{"slow", "hang", "broken", "fast"})
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+	assert.Len(t, results, 4)
+	assert.Equal(t, "slow", results[0].testcases[0].Name)
+	assert.Len(t, results[1].loadErrors, 1)
+	assert.Contains(t, results[1].loadErrors[0].Message, "timeout")
+	assert.Equal(t, "build failed", results[2].loadErrors[0].Message)
+	assert.Equal(t, "fast", results[3].testcases[0].Name)
+}
+
+func TestLoadPackageTimeoutKillsProcess(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	start := time.Now()
+	result := loadPackageWithTimeout("", "hang", 500*time.Millisecond, func(ctx context.Context, projPath string, packagePath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+		// bash启动的子进程继承输出管道，模拟go test -c启动的编译进程
+		_, _, err := ginkgoUtil.RunCommandWithContextAndOutput(ctx, "sleep 30 & echo $! > "+pidFile+"; wait", "", nil)
+		if err != nil {
+			return nil, []*sdkModel.LoadError{{Name: packagePath, Message: err.Error()}}
+		}
+		return []*ginkgoTestcase.TestCase{{Path: packagePath, Name: packagePath}}, nil
+	})
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Len(t, result.loadErrors, 1)
+	assert.Contains(t, result.loadErrors[0].Message, "timeout")
+	content, err := os.ReadFile(pidFile)
+	assert.NoError(t, err)
+	pid := strings.TrimSpace(string(content))
+	// 超时后子进程所在的进程组被终止，子进程不再运行(已退出或者等待被回收)
+	assert.Eventually(t, func() bool {
+		stat, err := os.ReadFile(filepath.Join("/proc", pid, "stat"))
+		if err != nil {
+			return true
+		}
+		fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+		return len(fields) > 0 && fields[0] == "Z"
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestLoadWorkerCountAndTimeout(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_WORKERCOUNT", "0")
+	assert.Greater(t, loadWorkerCount(), 0)
+	t.Setenv("TESTSOLAR_TTP_WORKERCOUNT", "3")
+	assert.Equal(t, 3, loadWorkerCount())
+	t.Setenv("TESTSOLAR_TTP_LOADTIMEOUT", "")
+	assert.Equal(t, defaultLoadTimeout, loadTimeout())
+	t.Setenv("TESTSOLAR_TTP_LOADTIMEOUT", "invalid")
+	assert.Equal(t, defaultLoadTimeout, loadTimeout())
+	t.Setenv("TESTSOLAR_TTP_LOADTIMEOUT", "0")
+	assert.Equal(t, time.Duration(0), loadTimeout())
+}
+
+func TestGinkgoV1Load(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	projPath, err := filepath.Abs("../../testdata")
+	assert.NoError(t, err)
+	pkgBin, err := builder.BuildTestPackage(projPath, "demo/v1", false)
+	assert.NoError(t, err)
+	defer os.Remove(pkgBin)
+	testcases, err := ginkgo_v1_load(context.Background(), projPath, "demo/v1", pkgBin)
+	assert.NoError(t, err)
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "Testcase v1 context it", testcases[0].Name)
+	assert.Equal(t, "demo/v1/v1_test.go", testcases[0].Path)
+	assert.Equal(t, "14", testcases[0].Attributes["line"])
+	assert.Equal(t, `["demo/v1/v1_test.go:12","demo/v1/v1_test.go:13"]`, testcases[0].Attributes["containerLocations"])
+	assert.Equal(t, "1", testcases[0].Attributes["ginkgoVersion"])
+
+	// 测试套使用自定义reporter时不会生成JUnit报告，此时回退为从标准输出中解析用例
+	fileExistsMock := gomonkey.ApplyFunc(ginkgoUtil.FileExists, func(path string) (bool, error) {
+		return false, nil
+	})
+	defer fileExistsMock.Reset()
+	testcases, err = ginkgo_v1_load(context.Background(), projPath, "demo/v1", pkgBin)
+	assert.NoError(t, err)
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "Testcase v1 context it", testcases[0].Name)
+	assert.Equal(t, "demo/v1/v1_test.go", testcases[0].Path)
+}
+
+func TestGetAvailableSuitePath(t *testing.T) {
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "suite/entry_test.go", `package suite
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func TestSuite(t *testing.T) {
+	RunSpecsWithDefaultAndCustomReporters(t, "Suite", nil)
+}
+`)
+	writeTestFile(t, projPath, "suite/noentry/specs_test.go", `package noentry
+
+import . "github.com/onsi/ginkgo/v2"
+
+var _ = It("never runs", func() {})
+`)
+	packageList, loadErrors, err := getAvailableSuitePath(projPath, projPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"suite"}, packageList)
+	assert.Len(t, loadErrors, 1)
+	assert.Equal(t, "suite/noentry", loadErrors[0].Name)
+	assert.Contains(t, loadErrors[0].Message, "RunSpecs")
+
+	testcases, loadErrors := DynamicLoadTestcaseInFile(projPath, filepath.Join(projPath, "suite/noentry/specs_test.go"))
+	assert.Len(t, testcases, 0)
+	assert.Len(t, loadErrors, 1)
+	assert.Equal(t, "suite/noentry/specs_test.go", loadErrors[0].Name)
+}
+
+func TestStaticLoadNoEntryPackage(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_PARSEMODE", ParseModeStatic)
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "suite/entry_test.go", `package suite
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func TestSuite(t *testing.T) {
+	RunSpecs(t, "Suite")
+}
+
+var _ = It("runs", func() {})
+`)
+	writeTestFile(t, projPath, "suite/noentry/specs_test.go", `package noentry
+
+import . "github.com/onsi/ginkgo/v2"
+
+var _ = It("never runs", func() {})
+`)
+	// 静态解析与动态加载一样不会加载没有调用RunSpecs的包中的用例，并上报相同的加载错误
+	testcases, loadErrors := LoadTestCase(projPath, "suite")
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "runs", testcases[0].Name)
+	assert.Len(t, loadErrors, 1)
+	assert.Equal(t, "suite/noentry", loadErrors[0].Name)
+	assert.Equal(t, noEntryMessage("suite/noentry"), loadErrors[0].Message)
+
+	testcases, loadErrors = LoadTestCase(projPath, "suite/noentry/specs_test.go")
+	assert.Len(t, testcases, 0)
+	assert.Len(t, loadErrors, 1)
+	assert.Equal(t, "suite/noentry/specs_test.go", loadErrors[0].Name)
+	assert.Equal(t, noEntryMessage("suite/noentry"), loadErrors[0].Message)
+}
+
+func TestDynamicLoadCompileErrors(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	t.Setenv("TESTSOLAR_TTP_PARSEMODE", ParseModeDynamic)
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "go.mod", "module broken\n\ngo 1.19\n")
+	writeTestFile(t, projPath, "broken/broken_test.go", `package broken
+
+import "testing"
+
+func TestBroken(t *testing.T) {
+	undefinedFn()
+	var x int = "s"
+}
+`)
+	// 编译错误的具体描述与go版本相关，只校验位置以及关键信息
+	assertCompileErrors := func(loadErrors []*sdkModel.LoadError) {
+		assert.Len(t, loadErrors, 2)
+		assert.Equal(t, "broken/broken_test.go:6", loadErrors[0].Name)
+		assert.Equal(t, "undefined: undefinedFn", loadErrors[0].Message)
+		assert.Equal(t, "broken/broken_test.go:7", loadErrors[1].Name)
+		assert.Contains(t, loadErrors[1].Message, "\n")
+	}
+	_, loadErrors := dynamicLoadTestcase(context.Background(), projPath, "broken")
+	assertCompileErrors(loadErrors)
+	// 只包含标准go测试的包同样按文件行上报编译错误
+	testcases, loadErrors := LoadTestCase(projPath, "broken")
+	assert.Len(t, testcases, 0)
+	assertCompileErrors(loadErrors)
+}
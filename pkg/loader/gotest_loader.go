@@ -0,0 +1,380 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	ginkgoBuilder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
+	ginkgoResult "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/result"
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+	"github.com/pkg/errors"
+)
+
+// goTestFile 单个测试文件中静态解析出的标准go测试
+type goTestFile struct {
+	testcases []*ginkgoTestcase.TestCase
+	// suiteEntries 调用了RunSpecs的ginkgo入口函数，不作为标准go测试上报
+	suiteEntries map[string]bool
+}
+
+// rewriteSubtestName 与testing包的处理保持一致，子测试名中的空白字符会被替换为下划线
+func rewriteSubtestName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+type goTestParser struct {
+	fset    *token.FileSet
+	relPath string
+}
+
+func (p *goTestParser) newTestCase(name string, pos token.Pos) *ginkgoTestcase.TestCase {
+	position := p.fset.Position(pos)
+	attributes := ginkgoResult.GenLocationAttributes(p.relPath, position.Line, position.Column, nil)
+	attributes["framework"] = ginkgoTestcase.FrameworkGoTest
+	return &ginkgoTestcase.TestCase{
+		Path:       p.relPath,
+		Name:       name,
+		Attributes: attributes,
+	}
+}
+
+// parseSubtests 解析函数体内通过`t.Run("name", func(t *testing.T) {...})`声明的子测试，只能识别名称为字符串常量的子测试
+func (p *goTestParser) parseSubtests(parent string, param string, body *ast.BlockStmt) []*ginkgoTestcase.TestCase {
+	var testcases []*ginkgoTestcase.TestCase
+	if param == "" || param == "_" || body == nil {
+		return nil
+	}
+	seen := map[string]int{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Run" {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != param {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		subName, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return true
+		}
+		subName = rewriteSubtestName(subName)
+		// 同名子测试会被testing包追加#01形式的序号
+		if count := seen[subName]; count > 0 {
+			seen[subName]++
+			subName = fmt.Sprintf("%s#%02d", subName, count)
+		} else {
+			seen[subName] = 1
+		}
+		name := parent + "/" + subName
+		testcases = append(testcases, p.newTestCase(name, call.Pos()))
+		if funcLit, ok := call.Args[1].(*ast.FuncLit); ok {
+			testcases = append(testcases, p.parseSubtests(name, funcParamName(funcLit.Type), funcLit.Body)...)
+		}
+		return false
+	})
+	return testcases
+}
+
+func funcParamName(funcType *ast.FuncType) string {
+	if funcType.Params == nil || len(funcType.Params.List) != 1 || len(funcType.Params.List[0].Names) != 1 {
+		return ""
+	}
+	return funcType.Params.List[0].Names[0].Name
+}
+
+// parseGoTestFile 静态解析测试文件中的顶层测试函数以及子测试
+func parseGoTestFile(projPath string, fset *token.FileSet, path string) (*goTestFile, error) {
+	node, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	relPath, err := filepath.Rel(projPath, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get rel path of %s", path)
+	}
+	p := &goTestParser{fset: fset, relPath: relPath}
+	result := &goTestFile{suiteEntries: map[string]bool{}}
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !ginkgoUtil.IsGoTestFunc(fn) {
+			continue
+		}
+		if ginkgoUtil.CallsRunSpecs(fn.Body) {
+			result.suiteEntries[fn.Name.Name] = true
+			continue
+		}
+		result.testcases = append(result.testcases, p.newTestCase(fn.Name.Name, fn.Name.Pos()))
+		result.testcases = append(result.testcases, p.parseSubtests(fn.Name.Name, funcParamName(fn.Type), fn.Body)...)
+	}
+	return result, nil
+}
+
+// parseGoTestInFiles 静态解析多个测试文件，返回标准go测试以及ginkgo入口函数名
+func parseGoTestInFiles(projPath string, files []string) ([]*ginkgoTestcase.TestCase, map[string]bool, []*sdkModel.LoadError) {
+	var testcases []*ginkgoTestcase.TestCase
+	var loadErrors []*sdkModel.LoadError
+	suiteEntries := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		result, err := parseGoTestFile(projPath, fset, file)
+		if err != nil {
+			log.Printf("parse go test in file %s failed, err: %v", file, err)
+			loadErrors = append(loadErrors, &sdkModel.LoadError{
+				Name:    file,
+				Message: err.Error(),
+			})
+			continue
+		}
+		testcases = append(testcases, result.testcases...)
+		for name := range result.suiteEntries {
+			suiteEntries[name] = true
+		}
+	}
+	return testcases, suiteEntries, loadErrors
+}
+
+// staticGoTestCases 静态解析files中的标准go测试以及包内的testify测试套
+// testify测试套的方法可以声明在包内任意文件中，因此总是解析整个包，执行测试套的入口函数不作为标准go测试上报
+func staticGoTestCases(projPath string, dir string, files []string) ([]*ginkgoTestcase.TestCase, map[string]bool, []*sdkModel.LoadError) {
+	goTestCases, suiteEntries, loadErrors := parseGoTestInFiles(projPath, files)
+	suites, err := ginkgoUtil.FindTestifySuites(dir)
+	if err != nil {
+		log.Printf("find testify suites in %s failed, err: %v", dir, err)
+		return goTestCases, suiteEntries, append(loadErrors, &sdkModel.LoadError{Name: dir, Message: err.Error()})
+	}
+	testifyEntries := map[string]bool{}
+	for _, suite := range suites {
+		testifyEntries[suite.Entry] = true
+	}
+	var testcases []*ginkgoTestcase.TestCase
+	for _, testcase := range goTestCases {
+		if !testifyEntries[strings.SplitN(testcase.Name, "/", 2)[0]] {
+			testcases = append(testcases, testcase)
+		}
+	}
+	testcases = append(testcases, genTestifyCases(projPath, dir, suites)...)
+	return testcases, suiteEntries, loadErrors
+}
+
+// ParseGoTestInPackage 静态解析目录对应包中的标准go测试函数、子测试以及testify测试套
+func ParseGoTestInPackage(projPath string, dir string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, []*sdkModel.LoadError{{Name: dir, Message: err.Error()}}
+	}
+	sort.Strings(files)
+	testcases, _, loadErrors := staticGoTestCases(projPath, dir, files)
+	return testcases, loadErrors
+}
+
+// ParseGoTestInFile 静态解析文件中的标准go测试函数、子测试以及声明在该文件中的testify测试方法
+func ParseGoTestInFile(projPath string, path string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	if !strings.HasSuffix(path, "_test.go") {
+		return nil, nil
+	}
+	testcases, _, loadErrors := staticGoTestCases(projPath, filepath.Dir(path), []string{path})
+	return filterTestCasesByPath(testcases, relPackagePath(projPath, path)), loadErrors
+}
+
+func filterTestCasesByPath(testcases []*ginkgoTestcase.TestCase, path string) []*ginkgoTestcase.TestCase {
+	var filtered []*ginkgoTestcase.TestCase
+	for _, testcase := range testcases {
+		if testcase.Path == path {
+			filtered = append(filtered, testcase)
+		}
+	}
+	return filtered
+}
+
+// listGoTests 通过测试二进制的`-test.list`参数列出包内的顶层测试函数
+func listGoTests(ctx context.Context, projPath, packagePath, pkgBin string) ([]string, error) {
+	cmdline := pkgBin + " -test.list '^Test'"
+	workDir := filepath.Join(projPath, packagePath)
+	log.Printf("list go tests cmd: %s in dir: %s", cmdline, workDir)
+	stdout, stderr, err := ginkgoUtil.RunCommandWithContextAndOutput(ctx, cmdline, workDir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list go tests command %s failed, err: %v, stderr: %s", cmdline, err, stderr)
+	}
+	var names []string
+	for _, line := range strings.Split(stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Test") && !strings.ContainsAny(line, " \t") {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+// dynamicLoadGoTestPackage 动态加载包内的标准go测试，以`-test.list`列出的顶层测试函数为准
+// 子测试以及用例定义位置只能通过静态解析获取，因此与静态解析结果合并
+func dynamicLoadGoTestPackage(ctx context.Context, projPath string, packagePath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	absPackagePath := filepath.Join(projPath, packagePath)
+	files, err := filepath.Glob(filepath.Join(absPackagePath, "*_test.go"))
+	if err != nil {
+		return nil, []*sdkModel.LoadError{{Name: packagePath, Message: err.Error()}}
+	}
+	sort.Strings(files)
+	staticCases, suiteEntries, _ := staticGoTestCases(projPath, absPackagePath, files)
+	pkgBin := findBinFile(projPath, packagePath)
+	if pkgBin == "" {
+		log.Printf("Can't find package bin file of %s during loading go tests, try to build it...", packagePath)
+		pkgBin, err = ginkgoBuilder.BuildTestPackageWithContext(ctx, projPath, packagePath, false)
+		if err != nil {
+			message := fmt.Sprintf("Build package %s during loading go tests failed, err: %s", packagePath, err.Error())
+			log.Println(message)
+			return nil, buildLoadErrors(packagePath, message, err)
+		}
+	}
+	names, err := listGoTests(ctx, projPath, packagePath, pkgBin)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, []*sdkModel.LoadError{{Name: packagePath, Message: err.Error()}}
+	}
+	subtests := map[string][]*ginkgoTestcase.TestCase{}
+	located := map[string]*ginkgoTestcase.TestCase{}
+	testifyCases := map[string][]*ginkgoTestcase.TestCase{}
+	for _, c := range staticCases {
+		if c.Attributes["framework"] == ginkgoTestcase.FrameworkTestify {
+			testifyCases[c.Attributes["suiteEntry"]] = append(testifyCases[c.Attributes["suiteEntry"]], c)
+		} else if top := strings.SplitN(c.Name, "/", 2); len(top) == 2 {
+			subtests[top[0]] = append(subtests[top[0]], c)
+		} else {
+			located[c.Name] = c
+		}
+	}
+	var caseList []*ginkgoTestcase.TestCase
+	for _, name := range names {
+		if suiteEntries[name] {
+			continue
+		}
+		// testify测试套的入口函数只作为执行测试套的入口，上报测试套中的测试方法
+		if cases, ok := testifyCases[name]; ok {
+			caseList = append(caseList, cases...)
+			continue
+		}
+		testcase, ok := located[name]
+		if !ok {
+			log.Printf("can't find location of go test %s in %s", name, packagePath)
+			testcase = &ginkgoTestcase.TestCase{
+				Path:       packagePath,
+				Name:       name,
+				Attributes: map[string]string{"framework": ginkgoTestcase.FrameworkGoTest},
+			}
+		}
+		caseList = append(caseList, testcase)
+		caseList = append(caseList, subtests[name]...)
+	}
+	return caseList, nil
+}
+
+// fileHasGoTests 判断文件中是否声明了标准go测试函数或者testify测试方法
+func fileHasGoTests(path string) bool {
+	dir, filename := filepath.Split(path)
+	goTests, err := ginkgoUtil.FindGoTests(dir)
+	if err != nil {
+		return false
+	}
+	for _, file := range goTests {
+		if file == filename {
+			return true
+		}
+	}
+	suites, err := ginkgoUtil.FindTestifySuites(dir)
+	if err != nil {
+		return false
+	}
+	for _, suite := range suites {
+		for _, method := range suite.Methods {
+			if method.File == filename {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadGoTests 加载selector路径下的标准go测试，加载模式与ginkgo用例保持一致
+func loadGoTests(projPath string, selectorAbsPath string, isDir bool, parseMode string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	var testcaseList []*ginkgoTestcase.TestCase
+	var loadErrors []*sdkModel.LoadError
+	if !isDir {
+		selectorPath := relPackagePath(projPath, selectorAbsPath)
+		if parseMode == ParseModeStatic {
+			testcases, lErrors := ParseGoTestInFile(projPath, selectorAbsPath)
+			return setLoadMode(testcases, ParseModeStatic), lErrors
+		}
+		if !fileHasGoTests(selectorAbsPath) {
+			return nil, nil
+		}
+		testcases, lErrors := dynamicLoadGoTestPackage(context.Background(), projPath, relPackagePath(projPath, filepath.Dir(selectorAbsPath)))
+		if len(lErrors) != 0 && parseMode == ParseModeHybrid {
+			warning := fallbackWarning(selectorPath, lErrors)
+			testcases, lErrors = ParseGoTestInFile(projPath, selectorAbsPath)
+			return setLoadMode(testcases, ParseModeStatic), append(lErrors, warning)
+		}
+		return setLoadMode(filterTestCasesByPath(testcases, selectorPath), ParseModeDynamic), lErrors
+	}
+	packageDirs, err := ginkgoUtil.FindGoTestPackages(selectorAbsPath)
+	if err != nil {
+		log.Printf("find go test packages in %s failed: %v", selectorAbsPath, err)
+		return nil, []*sdkModel.LoadError{{Name: selectorAbsPath, Message: err.Error()}}
+	}
+	var packageList []string
+	for _, dir := range packageDirs {
+		packageList = append(packageList, relPackagePath(projPath, dir))
+	}
+	if len(packageList) == 0 {
+		return nil, nil
+	}
+	log.Printf("Available go test package list: %v, root path: %s", packageList, selectorAbsPath)
+	if parseMode == ParseModeStatic {
+		for _, packagePath := range packageList {
+			testcases, lErrors := ParseGoTestInPackage(projPath, filepath.Join(projPath, packagePath))
+			testcaseList = append(testcaseList, setLoadMode(testcases, ParseModeStatic)...)
+			loadErrors = append(loadErrors, lErrors...)
+		}
+		return testcaseList, loadErrors
+	}
+	for i, result := range loadPackages(projPath, packageList, dynamicLoadGoTestPackage) {
+		if len(result.loadErrors) == 0 {
+			testcaseList = append(testcaseList, setLoadMode(result.testcases, ParseModeDynamic)...)
+			continue
+		}
+		if parseMode != ParseModeHybrid {
+			loadErrors = append(loadErrors, result.loadErrors...)
+			continue
+		}
+		packagePath := packageList[i]
+		log.Printf("Dynamic load go tests of package %s failed, fall back to static parsing", packagePath)
+		loadErrors = append(loadErrors, fallbackWarning(packagePath, result.loadErrors))
+		testcases, lErrors := ParseGoTestInPackage(projPath, filepath.Join(projPath, packagePath))
+		testcaseList = append(testcaseList, setLoadMode(testcases, ParseModeStatic)...)
+		loadErrors = append(loadErrors, lErrors...)
+	}
+	return testcaseList, loadErrors
+}
@@ -0,0 +1,126 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	"github.com/stretchr/testify/assert"
+)
+
+func goTestSelectors(testcases []*ginkgoTestcase.TestCase) []string {
+	var selectors []string
+	for _, testcase := range testcases {
+		selectors = append(selectors, testcase.GetSelector())
+	}
+	return selectors
+}
+
+func TestParseGoTestInPackage(t *testing.T) {
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "mixed/mixed_suite_test.go", `package mixed
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func TestMixed(t *testing.T) {
+	RunSpecs(t, "Mixed")
+}
+`)
+	writeTestFile(t, projPath, "mixed/plain_test.go", `package mixed
+
+import "testing"
+
+func TestPlain(tt *testing.T) {
+	tt.Run("first case", func(t *testing.T) {
+		t.Run("nested", func(t *testing.T) {})
+	})
+	tt.Run("first case", func(t *testing.T) {})
+	for _, name := range []string{"a", "b"} {
+		tt.Run(name, func(t *testing.T) {})
+	}
+}
+`)
+	testcases, loadErrors := ParseGoTestInPackage(projPath, filepath.Join(projPath, "mixed"))
+	assert.Len(t, loadErrors, 0)
+	assert.Equal(t, []string{
+		"mixed/plain_test.go?TestPlain",
+		"mixed/plain_test.go?TestPlain/first_case",
+		"mixed/plain_test.go?TestPlain/first_case/nested",
+		"mixed/plain_test.go?TestPlain/first_case#01",
+	}, goTestSelectors(testcases))
+	assert.Equal(t, ginkgoTestcase.FrameworkGoTest, testcases[0].Attributes["framework"])
+	assert.Equal(t, "5", testcases[0].Attributes["line"])
+	assert.Equal(t, "6", testcases[1].Attributes["line"])
+	assert.Equal(t, "mixed/plain_test.go", testcases[1].Attributes["file"])
+
+	testcases, loadErrors = ParseGoTestInFile(projPath, filepath.Join(projPath, "mixed/mixed_suite_test.go"))
+	assert.Len(t, loadErrors, 0)
+	assert.Len(t, testcases, 0)
+}
+
+func TestLoadGoTests(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	projPath, err := filepath.Abs("../../testdata")
+	assert.NoError(t, err)
+	defer os.Remove(filepath.Join(projPath, "gotest.test"))
+	expected := []string{
+		"gotest/gotest_test.go?TestAdd",
+		"gotest/gotest_test.go?TestAdd/positive_numbers",
+		"gotest/gotest_test.go?TestAdd/zero",
+		"gotest/gotest_test.go?TestReadFile",
+		"gotest/gotest_test.go?TestSkipped",
+	}
+	for _, parseMode := range []string{ParseModeStatic, ParseModeDynamic, ParseModeHybrid} {
+		t.Setenv("TESTSOLAR_TTP_PARSEMODE", parseMode)
+		testcases, loadErrors := LoadTestCase(projPath, "gotest")
+		assert.Len(t, loadErrors, 0)
+		assert.Equal(t, expected, goTestSelectors(testcases), parseMode)
+		for _, testcase := range testcases {
+			assert.Equal(t, ginkgoTestcase.FrameworkGoTest, testcase.Attributes["framework"])
+		}
+		testcases, loadErrors = LoadTestCase(projPath, "gotest/gotest_test.go")
+		assert.Len(t, loadErrors, 0)
+		assert.Equal(t, expected, goTestSelectors(testcases), parseMode)
+	}
+}
+
+func TestLoadTestifyTests(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	projPath, err := filepath.Abs("../../testdata")
+	assert.NoError(t, err)
+	defer os.Remove(filepath.Join(projPath, "testify.test"))
+	expected := []string{
+		"testify/broken_test.go?BrokenSetupSuite/TestNeverRuns",
+		"testify/broken_test.go?BrokenTeardownSuite/TestPasses",
+		"testify/user_test.go?UserSuite/TestCreate",
+		"testify/user_test.go?UserSuite/TestDelete",
+		"testify/user_test.go?UserSuite/TestFail",
+	}
+	for _, parseMode := range []string{ParseModeStatic, ParseModeDynamic, ParseModeHybrid} {
+		t.Setenv("TESTSOLAR_TTP_PARSEMODE", parseMode)
+		testcases, loadErrors := LoadTestCase(projPath, "testify")
+		assert.Len(t, loadErrors, 0)
+		selectors := goTestSelectors(testcases)
+		sort.Strings(selectors)
+		assert.Equal(t, expected, selectors, parseMode)
+		for _, testcase := range testcases {
+			assert.Equal(t, ginkgoTestcase.FrameworkTestify, testcase.Attributes["framework"])
+			assert.NotEmpty(t, testcase.Attributes["suiteEntry"])
+		}
+		// 入口函数所在的文件中没有测试方法
+		testcases, loadErrors = LoadTestCase(projPath, "testify/suite_test.go")
+		assert.Len(t, loadErrors, 0)
+		assert.Len(t, testcases, 0, parseMode)
+		testcases, loadErrors = LoadTestCase(projPath, "testify/user_test.go")
+		assert.Len(t, loadErrors, 0)
+		assert.Equal(t, expected[2:], goTestSelectors(testcases), parseMode)
+		assert.Equal(t, "UserSuite", testcases[0].Attributes["suite"])
+		assert.Equal(t, "TestUserSuite", testcases[0].Attributes["suiteEntry"])
+	}
+}
@@ -1,15 +1,25 @@
 package loader
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
 
 	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
 )
 
+// 加载用例的模式，通过环境变量TESTSOLAR_TTP_PARSEMODE指定，未指定或者指定为其他值时使用动态加载
+const (
+	ParseModeStatic  = "static"
+	ParseModeDynamic = "dynamic"
+	ParseModeHybrid  = "hybrid"
+)
+
 func LoadTestCase(projPath string, selectorPath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
 	var testcaseList []*ginkgoTestcase.TestCase
 	var loadErrors []*sdkModel.LoadError
@@ -25,31 +35,144 @@ func LoadTestCase(projPath string, selectorPath string) ([]*ginkgoTestcase.TestC
 	parseMode := os.Getenv("TESTSOLAR_TTP_PARSEMODE")
 	log.Printf("Try to load testcases from path %s, parse mode: %s", selectorAbsPath, parseMode)
 	if fi.IsDir() {
-		if parseMode != "static" {
-			loadedTestCases, lErrors := DynamicLoadTestcaseInDir(projPath, selectorAbsPath)
+		switch parseMode {
+		case ParseModeStatic:
+			loadedTestCases, lErrors := staticLoadTestcaseInDir(projPath, selectorAbsPath)
+			testcaseList = append(testcaseList, setLoadMode(loadedTestCases, ParseModeStatic)...)
+			loadErrors = append(loadErrors, lErrors...)
+		case ParseModeHybrid:
+			loadedTestCases, lErrors := hybridLoadTestcaseInDir(projPath, selectorAbsPath)
 			testcaseList = append(testcaseList, loadedTestCases...)
 			loadErrors = append(loadErrors, lErrors...)
-		} else {
-			err := filepath.Walk(selectorAbsPath, func(path string, fi os.FileInfo, _ error) error {
-				loadedTestCases, lErrors := ParseTestCaseInFile(projPath, path)
-				testcaseList = append(testcaseList, loadedTestCases...)
-				loadErrors = append(loadErrors, lErrors...)
-				return nil
-			})
-			if err != nil {
-				log.Printf("Failed to load testcases from %s, err: %s", selectorAbsPath, err)
-			}
+		default:
+			loadedTestCases, lErrors := DynamicLoadTestcaseInDir(projPath, selectorAbsPath)
+			testcaseList = append(testcaseList, setLoadMode(loadedTestCases, ParseModeDynamic)...)
+			loadErrors = append(loadErrors, lErrors...)
 		}
 	} else {
-		if parseMode != "static" {
+		switch parseMode {
+		case ParseModeStatic:
+			loadedTestCases, lErrors := staticLoadTestcaseInFile(projPath, selectorPath)
+			testcaseList = append(testcaseList, setLoadMode(loadedTestCases, ParseModeStatic)...)
+			loadErrors = append(loadErrors, lErrors...)
+		case ParseModeHybrid:
 			loadedTestCases, lErrors := DynamicLoadTestcaseInFile(projPath, selectorAbsPath)
-			testcaseList = append(testcaseList, loadedTestCases...)
+			if len(lErrors) != 0 {
+				loadErrors = append(loadErrors, fallbackWarning(selectorPath, lErrors))
+				loadedTestCases, lErrors = ParseTestCaseInFile(projPath, selectorAbsPath)
+				testcaseList = append(testcaseList, setLoadMode(loadedTestCases, ParseModeStatic)...)
+			} else {
+				testcaseList = append(testcaseList, setLoadMode(loadedTestCases, ParseModeDynamic)...)
+			}
 			loadErrors = append(loadErrors, lErrors...)
-		} else {
-			loadedTestCases, lErrors := ParseTestCaseInFile(projPath, selectorAbsPath)
-			testcaseList = append(testcaseList, loadedTestCases...)
+		default:
+			loadedTestCases, lErrors := DynamicLoadTestcaseInFile(projPath, selectorAbsPath)
+			testcaseList = append(testcaseList, setLoadMode(loadedTestCases, ParseModeDynamic)...)
 			loadErrors = append(loadErrors, lErrors...)
 		}
 	}
+	// 标准go测试(testing.T)与ginkgo用例使用相同的加载模式
+	goTestCases, lErrors := loadGoTests(projPath, selectorAbsPath, fi.IsDir(), parseMode)
+	testcaseList = append(testcaseList, goTestCases...)
+	loadErrors = append(loadErrors, lErrors...)
+	return testcaseList, uniqueLoadErrors(loadErrors)
+}
+
+// uniqueLoadErrors 去除重复的LoadError，同时包含ginkgo用例与标准go测试的包编译失败时两者会上报相同的编译错误
+func uniqueLoadErrors(loadErrors []*sdkModel.LoadError) []*sdkModel.LoadError {
+	var unique []*sdkModel.LoadError
+	seen := map[string]bool{}
+	for _, loadError := range loadErrors {
+		key := loadError.Name + "\n" + loadError.Message
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, loadError)
+	}
+	return unique
+}
+
+// staticLoadTestcaseInDir 以包为单位静态解析目录下的用例，以便识别声明在包内其他文件中的辅助函数
+// 与动态加载一样只解析调用了RunSpecs的测试套，导入了ginkgo但没有调用RunSpecs的包中的用例无法执行，以加载错误的形式返回
+func staticLoadTestcaseInDir(projPath string, rootPath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	var testcaseList []*ginkgoTestcase.TestCase
+	packageList, loadErrors, err := getAvailableSuitePath(projPath, rootPath)
+	if err != nil {
+		log.Printf("Failed to load testcases from %s, err: %s", rootPath, err)
+		return nil, []*sdkModel.LoadError{{Name: rootPath, Message: err.Error()}}
+	}
+	for _, packagePath := range packageList {
+		loadedTestCases, lErrors := ParseTestCaseInPackage(projPath, filepath.Join(projPath, packagePath))
+		testcaseList = append(testcaseList, loadedTestCases...)
+		loadErrors = append(loadErrors, lErrors...)
+	}
 	return testcaseList, loadErrors
 }
+
+// staticLoadTestcaseInFile 静态解析文件中的用例，文件所在的包导入了ginkgo但没有调用RunSpecs时与动态加载一样返回加载错误
+func staticLoadTestcaseInFile(projPath string, selectorPath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	packagePath := filepath.Dir(selectorPath)
+	packageDir := filepath.Join(projPath, packagePath)
+	if _, err := ginkgoUtil.FindSuiteFile(packageDir); err != nil {
+		if importsGinkgo, _ := ginkgoUtil.PackageImportsGinkgo(packageDir); importsGinkgo {
+			log.Printf("Package %s has ginkgo specs but no RunSpecs entrypoint", packagePath)
+			return nil, []*sdkModel.LoadError{{Name: selectorPath, Message: noEntryMessage(packagePath)}}
+		}
+	}
+	return ParseTestCaseInFile(projPath, filepath.Join(projPath, selectorPath))
+}
+
+// hybridLoadTestcaseInDir 逐个包动态加载用例，包编译或者dry run失败时回退为静态解析
+// 动态加载失败的原因会以警告的形式记录在LoadError中
+func hybridLoadTestcaseInDir(projPath string, rootPath string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	var testcaseList []*ginkgoTestcase.TestCase
+	var loadErrors []*sdkModel.LoadError
+	packageList, lErrors, err := getAvailableSuitePath(projPath, rootPath)
+	if err != nil {
+		log.Printf("get available suite path of %s failed: %v", rootPath, err)
+		loadErrors = append(loadErrors, &sdkModel.LoadError{
+			Name:    rootPath,
+			Message: err.Error(),
+		})
+		return nil, loadErrors
+	}
+	loadErrors = append(loadErrors, lErrors...)
+	log.Printf("Available package list: %v, root path: %s", packageList, rootPath)
+	for i, result := range dynamicLoadPackages(projPath, packageList) {
+		if len(result.loadErrors) == 0 {
+			testcaseList = append(testcaseList, setLoadMode(result.testcases, ParseModeDynamic)...)
+			continue
+		}
+		packagePath := packageList[i]
+		log.Printf("Dynamic load package %s failed, fall back to static parsing", packagePath)
+		loadErrors = append(loadErrors, fallbackWarning(packagePath, result.loadErrors))
+		caseList, lErrors := ParseTestCaseInPackage(projPath, filepath.Join(projPath, packagePath))
+		testcaseList = append(testcaseList, setLoadMode(caseList, ParseModeStatic)...)
+		loadErrors = append(loadErrors, lErrors...)
+	}
+	return testcaseList, loadErrors
+}
+
+// fallbackWarning 将动态加载失败的错误合并为一条警告，用于提示用例是通过静态解析加载的
+func fallbackWarning(name string, loadErrors []*sdkModel.LoadError) *sdkModel.LoadError {
+	var messages []string
+	for _, loadError := range loadErrors {
+		messages = append(messages, loadError.Message)
+	}
+	return &sdkModel.LoadError{
+		Name:    name,
+		Message: fmt.Sprintf("[WARNING] dynamic load failed, fall back to static parsing: %s", strings.Join(messages, "; ")),
+	}
+}
+
+// setLoadMode 在用例属性中记录加载用例时实际使用的模式
+func setLoadMode(testcases []*ginkgoTestcase.TestCase, parseMode string) []*ginkgoTestcase.TestCase {
+	for _, testcase := range testcases {
+		if testcase.Attributes == nil {
+			testcase.Attributes = map[string]string{}
+		}
+		testcase.Attributes["loadMode"] = parseMode
+	}
+	return testcases
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
@@ -33,6 +34,8 @@ func TestLoadTestCase(t *testing.T) {
 	err = builder.Build(absPath)
 	assert.NoError(t, err)
 	defer os.Remove("../../testdata/demo.test")
+	defer os.Remove("../../testdata/gotest.test")
+	defer os.Remove("../../testdata/testify.test")
 	defer os.Remove("../../testdata/demo/book.test")
 	defer os.Remove("../../testdata/demo/report.json")
 	// test dynamic loading testcase in directory
@@ -75,3 +78,106 @@ func TestLoadTestCase(t *testing.T) {
 	defer os.Remove("../../testdata/demo/book/report.json")
 	defer os.Remove("../../testdata/report.json")
 }
+
+func TestHybridLoadTestCase(t *testing.T) {
+	projPath := t.TempDir()
+	for _, name := range []string{"go.mod", "go.sum"} {
+		content, err := os.ReadFile(filepath.Join("../../testdata", name))
+		assert.NoError(t, err)
+		writeTestFile(t, projPath, name, string(content))
+	}
+	writeTestFile(t, projPath, "broken/broken_suite_test.go", `package broken
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func TestBroken(t *testing.T) {
+	RunSpecs(t, "Broken Suite")
+}
+`)
+	writeTestFile(t, projPath, "broken/broken_test.go", `package broken
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Broken", func() {
+	It("still loads", func() {
+		undefinedFunction()
+	})
+})
+`)
+	defer os.Setenv("TESTSOLAR_TTP_PARSEMODE", os.Getenv("TESTSOLAR_TTP_PARSEMODE"))
+	err := os.Setenv("TESTSOLAR_TTP_PARSEMODE", ParseModeHybrid)
+	assert.NoError(t, err)
+	testcases, loadErrors := LoadTestCase(projPath, "broken")
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "broken/broken_test.go?Broken still loads", testcases[0].GetSelector())
+	assert.Equal(t, ParseModeStatic, testcases[0].Attributes["loadMode"])
+	assert.Len(t, loadErrors, 1)
+	assert.Equal(t, "broken", loadErrors[0].Name)
+	assert.Contains(t, loadErrors[0].Message, "[WARNING]")
+
+	testcases, loadErrors = LoadTestCase(projPath, "broken/broken_test.go")
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, ParseModeStatic, testcases[0].Attributes["loadMode"])
+	assert.Len(t, loadErrors, 1)
+
+	// 可以正常编译的包仍然使用动态加载
+	absPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	defer os.Remove("../../testdata/table.test")
+	defer os.Remove("../../testdata/report.json")
+	testcases, loadErrors = LoadTestCase(absPath, "table")
+	assert.Len(t, loadErrors, 0)
+	assert.NotEmpty(t, testcases)
+	for _, testcase := range testcases {
+		assert.Equal(t, ParseModeDynamic, testcase.Attributes["loadMode"])
+	}
+}
+
+func TestLoadTestCaseInNestedModule(t *testing.T) {
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "go.mod", "module example.com/root\n\ngo 1.19\n")
+	goMod, err := os.ReadFile("../../testdata/go.mod")
+	assert.NoError(t, err)
+	writeTestFile(t, projPath, "sub/go.mod", strings.Replace(string(goMod), "module testdata", "module example.com/sub", 1))
+	goSum, err := os.ReadFile("../../testdata/go.sum")
+	assert.NoError(t, err)
+	writeTestFile(t, projPath, "sub/go.sum", string(goSum))
+	writeTestFile(t, projPath, "sub/nested/nested_suite_test.go", `package nested
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func TestNested(t *testing.T) {
+	RunSpecs(t, "Nested Suite")
+}
+`)
+	writeTestFile(t, projPath, "sub/nested/nested_test.go", `package nested
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Nested", func() {
+	It("is built from its own module", func() {})
+})
+`)
+	// go.work中没有声明sub模块时需要关闭workspace模式才能编译
+	writeTestFile(t, projPath, "go.work", "go 1.19\n\nuse .\n")
+	t.Setenv("TESTSOLAR_TTP_PARSEMODE", ParseModeDynamic)
+	t.Setenv("TESTSOLAR_TTP_LOADCACHE", "false")
+	testcases, loadErrors := LoadTestCase(projPath, "sub")
+	assert.Len(t, loadErrors, 0)
+	assert.Len(t, testcases, 1)
+	assert.Equal(t, "sub/nested/nested_test.go?Nested is built from its own module", testcases[0].GetSelector())
+	_, err = os.Stat(filepath.Join(projPath, "sub/nested.test"))
+	assert.NoError(t, err)
+}
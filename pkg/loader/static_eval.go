@@ -0,0 +1,337 @@
+package loader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"time"
+)
+
+// structValue 结构体字面量的值，键为字段名，无法计算的字段不会出现在其中
+type structValue map[string]interface{}
+
+// unresolvedValue 绑定到变量上但无法静态计算的值，只有在用例描述引用该变量时才会产生错误
+type unresolvedValue struct {
+	err error
+}
+
+var durationUnits = map[string]time.Duration{
+	"Nanosecond":  time.Nanosecond,
+	"Microsecond": time.Microsecond,
+	"Millisecond": time.Millisecond,
+	"Second":      time.Second,
+	"Minute":      time.Minute,
+	"Hour":        time.Hour,
+}
+
+// evalLiteral 计算字面量表达式的值，返回值类型与ginkgo运行时传入interface{}参数时的默认类型保持一致
+func evalLiteral(expr ast.Expr) (interface{}, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return strconv.Atoi(e.Value)
+		case token.FLOAT:
+			return strconv.ParseFloat(e.Value, 64)
+		case token.STRING:
+			return strconv.Unquote(e.Value)
+		case token.CHAR:
+			value, _, _, err := strconv.UnquoteChar(e.Value[1:len(e.Value)-1], '\'')
+			return value, err
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil":
+			return nil, nil
+		}
+	case *ast.ParenExpr:
+		return evalLiteral(e.X)
+	case *ast.UnaryExpr:
+		value, err := evalLiteral(e.X)
+		if err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case int:
+			if e.Op == token.SUB {
+				return -v, nil
+			}
+			return v, nil
+		case float64:
+			if e.Op == token.SUB {
+				return -v, nil
+			}
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("can't evaluate %s", types.ExprString(expr))
+}
+
+// constantValue 将常量转换为ginkgo运行时以interface{}传入参数时对应的值
+func constantValue(value constant.Value, typ types.Type) (interface{}, bool) {
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value), true
+	case constant.Bool:
+		return constant.BoolVal(value), true
+	case constant.Int:
+		if v, exact := constant.Int64Val(value); exact {
+			return int(v), true
+		}
+	case constant.Float:
+		v, _ := constant.Float64Val(value)
+		if basic, ok := typ.(*types.Basic); ok && basic.Kind() == types.Float32 {
+			return float32(v), true
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// lookupObject 查找标识符引用或定义的对象
+func (p *specParser) lookupObject(ident *ast.Ident) types.Object {
+	if obj := p.pkg.info.Uses[ident]; obj != nil {
+		return obj
+	}
+	return p.pkg.info.Defs[ident]
+}
+
+// evalExpr 计算表达式的值，支持字面量、包内常量及其拼接、参数均为常量的fmt.Sprintf调用
+// 以及内联辅助函数或展开循环时绑定的参数和循环变量
+func (p *specParser) evalExpr(expr ast.Expr) (interface{}, error) {
+	if tv, ok := p.pkg.info.Types[expr]; ok && tv.Value != nil {
+		if value, ok := constantValue(tv.Value, tv.Type); ok {
+			return value, nil
+		}
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if value, ok := p.env[p.lookupObject(e)]; ok {
+			if unresolved, ok := value.(unresolvedValue); ok {
+				return nil, unresolved.err
+			}
+			return value, nil
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			if _, bound := p.env[p.lookupObject(x)]; bound {
+				value, err := p.evalExpr(x)
+				if err != nil {
+					return nil, err
+				}
+				if fields, ok := value.(structValue); ok {
+					if field, ok := fields[e.Sel.Name]; ok {
+						return field, nil
+					}
+				}
+			}
+		}
+	case *ast.CompositeLit:
+		return p.evalStruct(e)
+	case *ast.ParenExpr:
+		return p.evalExpr(e.X)
+	case *ast.CallExpr:
+		if fun, ok := e.Fun.(*ast.SelectorExpr); ok {
+			if p.isFmtFunc(fun) && (fun.Sel.Name == "Sprintf" || fun.Sel.Name == "Sprint") {
+				var args []interface{}
+				for _, arg := range e.Args {
+					value, err := p.evalExpr(arg)
+					if err != nil {
+						return nil, err
+					}
+					args = append(args, value)
+				}
+				if fun.Sel.Name == "Sprint" {
+					return fmt.Sprint(args...), nil
+				}
+				if len(args) == 0 {
+					return nil, fmt.Errorf("missing format of %s", types.ExprString(expr))
+				}
+				format, ok := args[0].(string)
+				if !ok {
+					return nil, fmt.Errorf("format of %s is not a string", types.ExprString(expr))
+				}
+				return fmt.Sprintf(format, args[1:]...), nil
+			}
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			x, err := p.evalExpr(e.X)
+			if err != nil {
+				return nil, err
+			}
+			y, err := p.evalExpr(e.Y)
+			if err != nil {
+				return nil, err
+			}
+			xStr, xOk := x.(string)
+			yStr, yOk := y.(string)
+			if xOk && yOk {
+				return xStr + yStr, nil
+			}
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return p.evalExpr(e.X)
+		}
+	}
+	return evalLiteral(expr)
+}
+
+// isFmtFunc 判断选择器表达式是否引用了fmt包中的函数，通过类型检查结果解析包名，因此支持别名导入并排除同名的局部变量
+func (p *specParser) isFmtFunc(fun *ast.SelectorExpr) bool {
+	x, ok := fun.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	pkgName, ok := p.pkg.info.Uses[x].(*types.PkgName)
+	return ok && pkgName.Imported().Path() == "fmt"
+}
+
+// evalStruct 计算结构体字面量，字段名通过类型检查结果获取，因此同时支持带字段名和按顺序赋值的写法
+func (p *specParser) evalStruct(lit *ast.CompositeLit) (interface{}, error) {
+	tv, ok := p.pkg.info.Types[lit]
+	if !ok || tv.Type == nil {
+		return nil, fmt.Errorf("can't evaluate %s", types.ExprString(lit))
+	}
+	typ := tv.Type.Underlying()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem().Underlying()
+	}
+	st, ok := typ.(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("can't evaluate %s", types.ExprString(lit))
+	}
+	fields := structValue{}
+	for i, elt := range lit.Elts {
+		var name string
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			name, elt = key.Name, kv.Value
+		} else if i < st.NumFields() {
+			name = st.Field(i).Name()
+		} else {
+			continue
+		}
+		if value, err := p.evalExpr(elt); err == nil {
+			fields[name] = value
+		}
+	}
+	return fields, nil
+}
+
+func (p *specParser) evalString(expr ast.Expr) (string, error) {
+	value, err := p.evalExpr(expr)
+	if err != nil {
+		return "", err
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%v is not a string", value)
+	}
+	return str, nil
+}
+
+// evalDuration 计算形如`10 * time.Second`的时间表达式
+func (p *specParser) evalDuration(expr ast.Expr) (time.Duration, error) {
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		if unit, ok := durationUnits[e.Sel.Name]; ok {
+			return unit, nil
+		}
+	case *ast.ParenExpr:
+		return p.evalDuration(e.X)
+	case *ast.CallExpr:
+		// time.Duration(n)
+		if getCallName(e) == "Duration" && len(e.Args) == 1 {
+			return p.evalDuration(e.Args[0])
+		}
+	case *ast.BinaryExpr:
+		x, err := p.evalDuration(e.X)
+		if err != nil {
+			return 0, err
+		}
+		y, err := p.evalDuration(e.Y)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.MUL:
+			return x * y, nil
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		}
+	default:
+		value, err := p.evalExpr(expr)
+		if err != nil {
+			return 0, err
+		}
+		switch v := value.(type) {
+		case int:
+			return time.Duration(v), nil
+		case float64:
+			return time.Duration(v), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported duration %s", types.ExprString(expr))
+}
+
+// evalRange 展开range语句遍历的字面量集合，返回每次迭代对应的key和value
+// 支持切片、数组字面量，以初始值为字面量的包级变量以及整数
+// map的遍历顺序是随机的，无法确定用例的加载顺序，因此不展开遍历map的循环
+func (p *specParser) evalRange(expr ast.Expr) ([][2]interface{}, error) {
+	if tv, ok := p.pkg.info.Types[expr]; ok && tv.Type != nil {
+		if _, ok := tv.Type.Underlying().(*types.Map); ok {
+			return nil, fmt.Errorf("can't range over map %s in random order", types.ExprString(expr))
+		}
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		if value, ok := p.pkg.varValues[p.lookupObject(ident)]; ok {
+			return p.evalRange(value)
+		}
+	}
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		var iterations [][2]interface{}
+		for i, elt := range lit.Elts {
+			var key interface{} = i
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				keyValue, err := p.evalExpr(kv.Key)
+				if err != nil {
+					keyValue = unresolvedValue{err: err}
+				}
+				key, elt = keyValue, kv.Value
+			}
+			value, err := p.evalExpr(elt)
+			if err != nil {
+				value = unresolvedValue{err: err}
+			}
+			iterations = append(iterations, [2]interface{}{key, value})
+		}
+		return iterations, nil
+	}
+	value, err := p.evalExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := value.(int)
+	if !ok {
+		return nil, fmt.Errorf("can't range over %s", types.ExprString(expr))
+	}
+	var iterations [][2]interface{}
+	for i := 0; i < n; i++ {
+		iterations = append(iterations, [2]interface{}{i, nil})
+	}
+	return iterations, nil
+}
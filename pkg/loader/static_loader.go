@@ -3,201 +3,848 @@ package loader
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"log"
 	"os"
-	"reflect"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	ginkgoResult "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/result"
 	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
 
 	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
 )
 
-func genTestCaseBySpec(path string, spec *TestCaseSpec, root *ginkgoTestcase.TestCase) ([]*ginkgoTestcase.TestCase, error) {
-	var testcases []*ginkgoTestcase.TestCase
-	if root == nil {
-		root = &ginkgoTestcase.TestCase{
-			Path:       path,
-			Name:       spec.name,
-			Attributes: map[string]string{}, //TODO:
+// genTestCaseBySpec 根据用例节点生成用例，parents为从最外层容器到当前节点的父节点链
+// 标签以及装饰器会沿着容器层级向下继承，最终生成的属性与动态加载保持一致
+// 用例路径为叶子节点所在的文件，与动态加载时ginkgo上报的LeafNodeLocation保持一致
+func (p *specParser) genTestCaseBySpec(spec *TestCaseSpec, parents []*TestCaseSpec) []*ginkgoTestcase.TestCase {
+	if spec.leaf {
+		// 与ginkgo运行时保持一致，过滤掉空的容器节点名称后以空格拼接用例名
+		var containerNames []string
+		var hierarchyLabels [][]string
+		decorators := map[string]string{}
+		for _, parent := range parents {
+			if parent.name != "" {
+				containerNames = append(containerNames, parent.name)
+			}
+			hierarchyLabels = append(hierarchyLabels, parent.labels)
+			for k, v := range parent.decorators {
+				decorators[k] = v
+			}
 		}
-	} else {
-		root.Name += "/" + spec.name
-	}
-	if len(spec.subSpecs) == 0 {
-		testcases = append(testcases, root)
-		return testcases, nil
-	}
-	for _, subSpec := range spec.subSpecs {
-		newRoot := &ginkgoTestcase.TestCase{
-			Path:       root.Path,
-			Name:       root.Name,
-			Attributes: root.Attributes,
+		for k, v := range spec.decorators {
+			decorators[k] = v
 		}
-		newTestCases, err := genTestCaseBySpec(path, subSpec, newRoot)
-		if err != nil {
-			continue
+		containerName := strings.Join(containerNames, " ")
+		leafName := spec.name
+		without, _ := strconv.ParseBool(os.Getenv("TESTSOLAR_TTP_WITHOUTLABELS"))
+		if !without {
+			leafName = ginkgoResult.AddLabels(spec.name, hierarchyLabels, spec.labels)
+		}
+		specName := strings.TrimSpace(strings.Join(append(containerNames, spec.name), " "))
+		attributes := ginkgoResult.GenCaseAttributes(containerName, leafName, specName, ginkgoResult.GetLabels(hierarchyLabels, spec.labels))
+		for k, v := range decorators {
+			attributes[k] = v
+		}
+		var containerLocations []string
+		for _, parent := range parents {
+			containerLocations = append(containerLocations, fmt.Sprintf("%s:%d", p.relPath(parent.pos), p.pkg.fset.Position(parent.pos).Line))
+		}
+		position := p.pkg.fset.Position(spec.pos)
+		for k, v := range ginkgoResult.GenLocationAttributes(p.relPath(spec.pos), position.Line, position.Column, containerLocations) {
+			attributes[k] = v
 		}
-		testcases = append(testcases, newTestCases...)
+		return []*ginkgoTestcase.TestCase{
+			{
+				Path:       p.relPath(spec.pos),
+				Name:       strings.TrimSpace(strings.Join([]string{containerName, leafName}, " ")),
+				Attributes: attributes,
+			},
+		}
+	}
+	var testcases []*ginkgoTestcase.TestCase
+	subParents := append(parents[:len(parents):len(parents)], spec)
+	for _, subSpec := range spec.subSpecs {
+		testcases = append(testcases, p.genTestCaseBySpec(subSpec, subParents)...)
 	}
-	return testcases, nil
+	return testcases
 }
 
 type TestCaseSpec struct {
-	kind     string
-	name     string
-	subSpecs []*TestCaseSpec
+	pos        token.Pos
+	kind       string
+	name       string
+	leaf       bool
+	labels     []string
+	decorators map[string]string
+	subSpecs   []*TestCaseSpec
+}
+
+// specParser 静态解析用例节点，无法解析的用例描述会以LoadError的形式记录并指向对应的文件及行号
+type specParser struct {
+	projPath   string
+	pkg        *staticPackage
+	loadErrors []*sdkModel.LoadError
+	// env 内联辅助函数以及展开循环时绑定到参数和循环变量上的值
+	env map[types.Object]interface{}
+	// inlining 正在内联的辅助函数，避免递归调用导致无限展开
+	inlining map[*ast.FuncDecl]bool
+}
+
+func (p *specParser) relPath(pos token.Pos) string {
+	filename := p.pkg.fset.Position(pos).Filename
+	if relPath, err := filepath.Rel(p.projPath, filename); err == nil {
+		return relPath
+	}
+	return filename
+}
+
+// withEnv 在绑定了额外变量的环境中执行fn，执行结束后恢复原有环境
+func (p *specParser) withEnv(bindings map[types.Object]interface{}, fn func()) {
+	saved := p.env
+	env := make(map[types.Object]interface{}, len(saved)+len(bindings))
+	for k, v := range saved {
+		env[k] = v
+	}
+	for k, v := range bindings {
+		env[k] = v
+	}
+	p.env = env
+	defer func() { p.env = saved }()
+	fn()
+}
+
+// specError 静态解析用例节点失败时的错误，记录出错的文件及行号
+type specError struct {
+	position string
+	message  string
+}
+
+func (e *specError) Error() string {
+	return fmt.Sprintf("%s: %s", e.position, e.message)
+}
+
+func (p *specParser) newError(node ast.Node, format string, args ...interface{}) error {
+	position := p.pkg.fset.Position(node.Pos())
+	return &specError{
+		position: fmt.Sprintf("%s:%d", p.relPath(node.Pos()), position.Line),
+		message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *specParser) reportError(err error) {
+	log.Printf("Static parse ginkgo testcase failed: %v", err)
+	loadError := &sdkModel.LoadError{
+		Name:    err.Error(),
+		Message: err.Error(),
+	}
+	if specErr, ok := err.(*specError); ok {
+		loadError.Name = specErr.position
+		loadError.Message = specErr.message
+	}
+	p.loadErrors = append(p.loadErrors, loadError)
+}
+
+// ginkgo装饰器，表格用例中Entry的参数需要排除装饰器后才是实际传入表格函数的参数
+var ginkgoDecorators = []string{
+	"Label", "Offset", "FlakeAttempts", "MustPassRepeatedly", "NodeTimeout", "SpecTimeout", "GracePeriod",
+	"PollProgressAfter", "PollProgressInterval", "SuppressProgressReporting", "Focus", "Pending", "Serial",
+	"Ordered", "ContinueOnFailure", "OncePerOrdered",
 }
 
-func parseTestCaseSpec(expr *ast.CallExpr) (*TestCaseSpec, error) {
-	testcaseSpec := &TestCaseSpec{}
-	name := ""
-	exprName := reflect.TypeOf(expr.Fun).String()
-	if exprName == "*ast.Ident" {
-		name = expr.Fun.(*ast.Ident).Name
-	} else if exprName == "*ast.SelectorExpr" {
-		if selectorExpr, ok := expr.Fun.(*ast.SelectorExpr); ok {
-			name = selectorExpr.Sel.Name
+func getCallName(expr *ast.CallExpr) string {
+	switch fun := expr.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+func getExprName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.CallExpr:
+		return getCallName(e)
+	}
+	return ""
+}
+
+func isDecorator(expr ast.Expr) bool {
+	name := getExprName(expr)
+	for _, decorator := range ginkgoDecorators {
+		if name == decorator {
+			return true
 		}
-	} else {
-		return nil, nil
 	}
-	if name == "Describe" || name == "Context" || name == "It" {
-		testcaseSpec.kind = name
-		args := expr.Args
-		if len(args) != 2 && len(args) != 3 {
-			return nil, fmt.Errorf("Invalid ginkgo spec %s", name)
+	return false
+}
+
+// 装饰器与用例属性键的对应关系
+var decoratorAttributes = map[string]string{
+	"Ordered":            "ordered",
+	"Serial":             "serial",
+	"Focus":              "focused",
+	"Pending":            "pending",
+	"FlakeAttempts":      "flakeAttempts",
+	"MustPassRepeatedly": "mustPassRepeatedly",
+	"NodeTimeout":        "nodeTimeout",
+	"SpecTimeout":        "specTimeout",
+}
+
+// parseDecorators 解析节点上声明的装饰器，记录标签以及需要上报的装饰器属性
+// 标签会拼接到用例名中，因此无法计算的标签会返回错误
+func (p *specParser) parseDecorators(spec *TestCaseSpec, args []ast.Expr) error {
+	for _, arg := range args {
+		if !isDecorator(arg) {
+			continue
 		}
-		switch args[0].(type) {
-		case *ast.BasicLit:
-			arg1 := args[0].(*ast.BasicLit)
-			if arg1.Kind == token.STRING {
-				testcaseSpec.name = strings.Replace(arg1.Value, "\"", "", -1)
+		name := getExprName(arg)
+		call, isCall := arg.(*ast.CallExpr)
+		switch name {
+		case "Label":
+			if !isCall {
+				continue
 			}
+			for _, labelArg := range call.Args {
+				label, err := p.evalString(labelArg)
+				if err != nil {
+					return p.newError(labelArg, "Unsupported label %s: %v", types.ExprString(labelArg), err)
+				}
+				if !ginkgoUtil.ElementIsInSlice(label, spec.labels) {
+					spec.labels = append(spec.labels, label)
+				}
+			}
+		case "Ordered", "Serial", "Focus", "Pending":
+			spec.setDecorator(decoratorAttributes[name], "true")
+		case "FlakeAttempts", "MustPassRepeatedly":
+			if !isCall || len(call.Args) != 1 {
+				continue
+			}
+			value, err := p.evalExpr(call.Args[0])
+			if err != nil {
+				log.Printf("Unsupported decorator %s: %v", types.ExprString(arg), err)
+				continue
+			}
+			spec.setDecorator(decoratorAttributes[name], fmt.Sprint(value))
+		case "NodeTimeout", "SpecTimeout":
+			if !isCall || len(call.Args) != 1 {
+				continue
+			}
+			duration, err := p.evalDuration(call.Args[0])
+			if err != nil {
+				log.Printf("Unsupported decorator %s: %v", types.ExprString(arg), err)
+				spec.setDecorator(decoratorAttributes[name], types.ExprString(call.Args[0]))
+				continue
+			}
+			spec.setDecorator(decoratorAttributes[name], duration.String())
+		}
+	}
+	return nil
+}
+
+func (spec *TestCaseSpec) setDecorator(key, value string) {
+	if spec.decorators == nil {
+		spec.decorators = map[string]string{}
+	}
+	spec.decorators[key] = value
+}
+
+// findBody 查找节点参数中的函数体，ginkgo要求函数体为节点的最后一个参数
+// 函数体既可以是函数字面量，也可以是包内声明的函数
+func (p *specParser) findBody(args []ast.Expr) *ast.BlockStmt {
+	for i := len(args) - 1; i >= 0; i-- {
+		switch arg := args[i].(type) {
+		case *ast.FuncLit:
+			return arg.Body
 		case *ast.Ident:
-			arg1 := args[0].(*ast.Ident)
-			log.Printf("Unsupported testcase description: %v", arg1)
+			if decl, ok := p.pkg.funcDecls[p.lookupObject(arg)]; ok {
+				return decl.Body
+			}
+		}
+	}
+	return nil
+}
+
+// ginkgo v1/v2中的容器节点、用例节点以及表格节点，均支持F(focus)、P/X(pending)前缀的变体
+var (
+	containerNodes = []string{"Describe", "Context", "When"}
+	subjectNodes   = []string{"It", "Specify", "Measure"}
+	tableNodes     = []string{"DescribeTable", "DescribeTableSubtree"}
+)
+
+func isGinkgoNode(name string) bool {
+	return ginkgoUtil.ElementIsInSlice(name, containerNodes) ||
+		ginkgoUtil.ElementIsInSlice(name, subjectNodes) ||
+		ginkgoUtil.ElementIsInSlice(name, tableNodes) ||
+		name == "Entry"
+}
+
+// parseNodeKind 解析节点名称对应的节点类型，并返回节点是否为focus或pending状态
+// 例如FDescribe返回(Describe, true, false)，XIt返回(It, false, true)，非ginkgo节点返回空字符串
+func parseNodeKind(name string) (kind string, focused bool, pending bool) {
+	if isGinkgoNode(name) {
+		return name, false, false
+	}
+	if len(name) > 1 && isGinkgoNode(name[1:]) {
+		switch name[0] {
+		case 'F':
+			return name[1:], true, false
+		case 'P', 'X':
+			return name[1:], false, true
 		}
+	}
+	return "", false, false
+}
+
+func (spec *TestCaseSpec) markFocusAndPending(focused, pending bool) {
+	if focused {
+		spec.setDecorator(decoratorAttributes["Focus"], "true")
+	}
+	if pending {
+		spec.setDecorator(decoratorAttributes["Pending"], "true")
+	}
+}
+
+func isTableEntry(name string) bool {
+	kind, _, _ := parseNodeKind(name)
+	return kind == "Entry"
+}
+
+// parseEntryDescription 解析EntryDescription("format")形式的描述，不是该形式时返回false
+func (p *specParser) parseEntryDescription(expr ast.Expr) (string, bool, error) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || getCallName(call) != "EntryDescription" || len(call.Args) != 1 {
+		return "", false, nil
+	}
+	format, err := p.evalString(call.Args[0])
+	return format, true, err
+}
+
+// isDescriptionFunc 判断函数字面量是否为返回单个字符串的描述函数
+func isDescriptionFunc(funcLit *ast.FuncLit) bool {
+	results := funcLit.Type.Results
+	if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+		return false
+	}
+	ident, ok := results.List[0].Type.(*ast.Ident)
+	return ok && ident.Name == "string"
+}
 
-		if name == "It" {
-			// leaf node
-			return testcaseSpec, nil
+func defaultEntryDescription(parameters []interface{}) string {
+	var out []string
+	for _, parameter := range parameters {
+		out = append(out, fmt.Sprint(parameter))
+	}
+	return "Entry: " + strings.Join(out, ", ")
+}
+
+type tableEntryDescription struct {
+	format      string
+	hasFormat   bool
+	unsupported bool
+}
+
+// parseTableEntrySpec 按照ginkgo运行时的规则生成表格用例中单个Entry的用例名
+func (p *specParser) parseTableEntrySpec(expr *ast.CallExpr, tableDescription *tableEntryDescription) (*TestCaseSpec, error) {
+	name := getCallName(expr)
+	if len(expr.Args) == 0 {
+		return nil, p.newError(expr, "Invalid ginkgo table entry %s", name)
+	}
+	// 仅在用例名依赖Entry参数时才计算参数的值
+	evalParameters := func() ([]interface{}, error) {
+		var parameters []interface{}
+		for _, arg := range expr.Args[1:] {
+			if isDecorator(arg) {
+				continue
+			}
+			value, err := p.evalExpr(arg)
+			if err != nil {
+				return nil, p.newError(arg, "Unsupported ginkgo table entry parameter %s: %v", types.ExprString(arg), err)
+			}
+			parameters = append(parameters, value)
+		}
+		return parameters, nil
+	}
+	kind, focused, pending := parseNodeKind(name)
+	spec := &TestCaseSpec{pos: expr.Pos(), kind: kind, leaf: true}
+	spec.markFocusAndPending(focused, pending)
+	if err := p.parseDecorators(spec, expr.Args[1:]); err != nil {
+		return nil, err
+	}
+	description := expr.Args[0]
+	if ident, ok := description.(*ast.Ident); ok && ident.Name == "nil" {
+		if tableDescription.unsupported {
+			return nil, p.newError(expr, "Unsupported ginkgo table entry description function")
+		}
+		parameters, err := evalParameters()
+		if err != nil {
+			return nil, err
+		}
+		if tableDescription.hasFormat {
+			spec.name = fmt.Sprintf(tableDescription.format, parameters...)
+		} else {
+			spec.name = defaultEntryDescription(parameters)
+		}
+		return spec, nil
+	}
+	if format, ok, err := p.parseEntryDescription(description); ok {
+		if err != nil {
+			return nil, p.newError(description, "Unsupported ginkgo table entry description: %v", err)
 		}
-		index := 1
-		if len(args) == 3 {
-			index = 2
+		parameters, err := evalParameters()
+		if err != nil {
+			return nil, err
 		}
-		arg2 := args[index].(*ast.FuncLit)
-		for _, it := range arg2.Body.List {
-			switch it.(type) {
-			case *ast.ExprStmt:
-				subSpec, err := parseTestCaseSpec(it.(*ast.ExprStmt).X.(*ast.CallExpr))
+		spec.name = fmt.Sprintf(format, parameters...)
+		return spec, nil
+	}
+	entryName, err := p.evalString(description)
+	if err != nil {
+		return nil, p.newError(description, "Unsupported ginkgo table entry description %s: %v", types.ExprString(description), err)
+	}
+	spec.name = entryName
+	return spec, nil
+}
+
+// parseTableSpec 将DescribeTable/DescribeTableSubtree展开为每个Entry对应的用例
+func (p *specParser) parseTableSpec(expr *ast.CallExpr, kind string) (*TestCaseSpec, error) {
+	args := expr.Args
+	if len(args) < 2 {
+		return nil, p.newError(expr, "Invalid ginkgo spec %s", getCallName(expr))
+	}
+	testcaseSpec := &TestCaseSpec{pos: expr.Pos(), kind: kind}
+	tableName, err := p.evalString(args[0])
+	if err != nil {
+		return nil, p.newError(args[0], "Unsupported testcase description %s: %v", types.ExprString(args[0]), err)
+	}
+	testcaseSpec.name = tableName
+	if err := p.parseDecorators(testcaseSpec, args[1:]); err != nil {
+		return nil, err
+	}
+	var entries []*ast.CallExpr
+	var body *ast.BlockStmt
+	tableDescription := &tableEntryDescription{}
+	for _, arg := range args[1:] {
+		switch arg := arg.(type) {
+		case *ast.CallExpr:
+			if isTableEntry(getCallName(arg)) {
+				entries = append(entries, arg)
+			} else if format, ok, err := p.parseEntryDescription(arg); ok {
 				if err != nil {
-					log.Printf("Parse ginkgo testcase failed: %v", err)
-					continue
+					log.Printf("Unsupported table entry description: %v", err)
+					tableDescription.unsupported = true
+				} else {
+					tableDescription.format = format
+					tableDescription.hasFormat = true
 				}
-				if subSpec != nil {
-					testcaseSpec.subSpecs = append(testcaseSpec.subSpecs, subSpec)
-				}
-			case *ast.DeclStmt:
-				if len(it.(*ast.DeclStmt).Decl.(*ast.GenDecl).Specs) == 0 {
-					continue
-				}
-				if len(it.(*ast.DeclStmt).Decl.(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values) == 0 {
-					continue
+			}
+		case *ast.CompositeLit:
+			for _, elt := range arg.Elts {
+				if call, ok := elt.(*ast.CallExpr); ok && isTableEntry(getCallName(call)) {
+					entries = append(entries, call)
 				}
-				if subExpr, ok := it.(*ast.DeclStmt).Decl.(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.CallExpr); ok {
-					subSpec, err := parseTestCaseSpec(subExpr)
-					if err != nil {
-						log.Printf("Parse ginkgo testcase failed: %v", err)
-						continue
-					}
-					if subSpec != nil {
-						testcaseSpec.subSpecs = append(testcaseSpec.subSpecs, subSpec)
-					}
+			}
+		case *ast.FuncLit:
+			if isDescriptionFunc(arg) {
+				tableDescription.unsupported = true
+			} else {
+				body = arg.Body
+			}
+		}
+	}
+	var bodySpecs []*TestCaseSpec
+	if kind == "DescribeTableSubtree" && body != nil {
+		bodySpecs = p.parseStmts(body.List)
+	}
+	for _, entry := range entries {
+		entrySpec, err := p.parseTableEntrySpec(entry, tableDescription)
+		if err != nil {
+			p.reportError(err)
+			continue
+		}
+		if kind == "DescribeTableSubtree" {
+			// 子树表格中每个Entry作为容器节点，表格函数体中声明的用例作为其子节点
+			entrySpec.leaf = false
+			entrySpec.subSpecs = bodySpecs
+		}
+		testcaseSpec.subSpecs = append(testcaseSpec.subSpecs, entrySpec)
+	}
+	return testcaseSpec, nil
+}
+
+// parseStmts 解析容器函数体中的语句，返回其中声明的用例节点
+func (p *specParser) parseStmts(stmts []ast.Stmt) []*TestCaseSpec {
+	var subSpecs []*TestCaseSpec
+	for _, stmt := range stmts {
+		subSpecs = append(subSpecs, p.parseStmt(stmt)...)
+	}
+	return subSpecs
+}
+
+// parseStmt 递归解析单条语句中声明的用例节点
+// 条件分支以及无法展开的循环中声明的用例在运行时不一定会被注册，这些用例会被标记为conditional
+func (p *specParser) parseStmt(stmt ast.Stmt) []*TestCaseSpec {
+	switch stmt := stmt.(type) {
+	case *ast.ExprStmt:
+		if call, ok := stmt.X.(*ast.CallExpr); ok {
+			return p.parseCall(call)
+		}
+	case *ast.DeclStmt:
+		genDecl, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok {
+			return nil
+		}
+		var subSpecs []*TestCaseSpec
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for _, value := range valueSpec.Values {
+				if call, ok := value.(*ast.CallExpr); ok {
+					subSpecs = append(subSpecs, p.parseCall(call)...)
 				}
 			}
 		}
+		return subSpecs
+	case *ast.AssignStmt:
+		var subSpecs []*TestCaseSpec
+		for _, value := range stmt.Rhs {
+			if call, ok := value.(*ast.CallExpr); ok {
+				subSpecs = append(subSpecs, p.parseCall(call)...)
+			}
+		}
+		return subSpecs
+	case *ast.BlockStmt:
+		return p.parseStmts(stmt.List)
+	case *ast.LabeledStmt:
+		return p.parseStmt(stmt.Stmt)
+	case *ast.IfStmt:
+		// 条件为常量时只解析实际执行的分支
+		if tv, ok := p.pkg.info.Types[stmt.Cond]; ok && tv.Value != nil && tv.Value.Kind() == constant.Bool {
+			if constant.BoolVal(tv.Value) {
+				return p.parseStmts(stmt.Body.List)
+			}
+			if stmt.Else != nil {
+				return p.parseStmt(stmt.Else)
+			}
+			return nil
+		}
+		subSpecs := p.parseStmts(stmt.Body.List)
+		if stmt.Else != nil {
+			subSpecs = append(subSpecs, p.parseStmt(stmt.Else)...)
+		}
+		return markConditional(subSpecs)
+	case *ast.SwitchStmt:
+		return markConditional(p.parseStmts(stmt.Body.List))
+	case *ast.TypeSwitchStmt:
+		return markConditional(p.parseStmts(stmt.Body.List))
+	case *ast.SelectStmt:
+		return markConditional(p.parseStmts(stmt.Body.List))
+	case *ast.CaseClause:
+		return p.parseStmts(stmt.Body)
+	case *ast.CommClause:
+		return p.parseStmts(stmt.Body)
+	case *ast.ForStmt:
+		return markConditional(p.parseStmts(stmt.Body.List))
+	case *ast.RangeStmt:
+		return p.parseRangeStmt(stmt)
+	}
+	return nil
+}
+
+// markConditional 将条件声明的用例节点标记为conditional，子节点通过继承获得该属性
+func markConditional(specs []*TestCaseSpec) []*TestCaseSpec {
+	for _, spec := range specs {
+		spec.setDecorator("conditional", "true")
+	}
+	return specs
+}
+
+// parseRangeStmt 展开遍历字面量集合的循环，每次迭代将循环变量绑定为对应的元素后解析循环体
+func (p *specParser) parseRangeStmt(stmt *ast.RangeStmt) []*TestCaseSpec {
+	iterations, err := p.evalRange(stmt.X)
+	if err != nil {
+		// 无法展开的循环仅解析一次循环体，循环变量无法计算，依赖循环变量的用例描述会上报加载错误
+		log.Printf("Unsupported range statement %s: %v", types.ExprString(stmt.X), err)
+		var subSpecs []*TestCaseSpec
+		p.withEnv(p.bindRangeVars(stmt, [2]interface{}{unresolvedValue{err: err}, unresolvedValue{err: err}}), func() {
+			subSpecs = p.parseStmts(stmt.Body.List)
+		})
+		return markConditional(subSpecs)
+	}
+	var subSpecs []*TestCaseSpec
+	for _, iteration := range iterations {
+		p.withEnv(p.bindRangeVars(stmt, iteration), func() {
+			subSpecs = append(subSpecs, p.parseStmts(stmt.Body.List)...)
+		})
+	}
+	return subSpecs
+}
+
+// bindRangeVars 将一次迭代的key和value绑定到循环变量上
+func (p *specParser) bindRangeVars(stmt *ast.RangeStmt, iteration [2]interface{}) map[types.Object]interface{} {
+	bindings := map[types.Object]interface{}{}
+	for i, expr := range []ast.Expr{stmt.Key, stmt.Value} {
+		if ident, ok := expr.(*ast.Ident); ok && ident.Name != "_" {
+			if obj := p.lookupObject(ident); obj != nil {
+				bindings[obj] = iteration[i]
+			}
+		}
+	}
+	return bindings
+}
+
+// parseCall 解析函数调用，ginkgo节点直接解析为用例节点，包内辅助函数则内联其函数体后解析
+func (p *specParser) parseCall(call *ast.CallExpr) []*TestCaseSpec {
+	spec, err := p.parseTestCaseSpec(call)
+	if err != nil {
+		p.reportError(err)
+		return nil
+	}
+	if spec != nil {
+		return []*TestCaseSpec{spec}
+	}
+	return p.inlineHelper(call)
+}
+
+// inlineHelper 内联包内的辅助函数，例如`func ItBehavesLikeCache(name string) { It(...) }`
+// 函数参数会绑定为调用时传入的值，以便计算依赖参数的用例描述
+func (p *specParser) inlineHelper(call *ast.CallExpr) []*TestCaseSpec {
+	if funcLit, ok := call.Fun.(*ast.FuncLit); ok {
+		// 立即执行的函数字面量，例如`func() { It(...) }()`
+		return p.parseStmts(funcLit.Body.List)
+	}
+	ident, ok := call.Fun.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	decl, ok := p.pkg.funcDecls[p.lookupObject(ident)]
+	if !ok || p.inlining[decl] {
+		return nil
+	}
+	bindings := map[types.Object]interface{}{}
+	argIndex := 0
+	for _, field := range decl.Type.Params.List {
+		_, variadic := field.Type.(*ast.Ellipsis)
+		for _, name := range field.Names {
+			if argIndex >= len(call.Args) {
+				break
+			}
+			obj := p.pkg.info.Defs[name]
+			if obj == nil {
+				argIndex++
+				continue
+			}
+			if variadic {
+				bindings[obj] = unresolvedValue{err: fmt.Errorf("can't evaluate variadic parameter %s", name.Name)}
+				continue
+			}
+			value, err := p.evalExpr(call.Args[argIndex])
+			if err != nil {
+				value = unresolvedValue{err: err}
+			}
+			bindings[obj] = value
+			argIndex++
+		}
+	}
+	if p.inlining == nil {
+		p.inlining = map[*ast.FuncDecl]bool{}
+	}
+	p.inlining[decl] = true
+	defer delete(p.inlining, decl)
+	var subSpecs []*TestCaseSpec
+	p.withEnv(bindings, func() {
+		subSpecs = p.parseStmts(decl.Body.List)
+	})
+	return subSpecs
+}
+
+func (p *specParser) parseTestCaseSpec(expr *ast.CallExpr) (*TestCaseSpec, error) {
+	name := getCallName(expr)
+	kind, focused, pending := parseNodeKind(name)
+	if kind == "" || kind == "Entry" {
+		return nil, nil
+	}
+	var testcaseSpec *TestCaseSpec
+	var err error
+	if ginkgoUtil.ElementIsInSlice(kind, tableNodes) {
+		testcaseSpec, err = p.parseTableSpec(expr, kind)
+	} else {
+		testcaseSpec, err = p.parseNodeSpec(expr, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	testcaseSpec.markFocusAndPending(focused, pending)
+	return testcaseSpec, nil
+}
+
+func (p *specParser) parseNodeSpec(expr *ast.CallExpr, kind string) (*TestCaseSpec, error) {
+	testcaseSpec := &TestCaseSpec{pos: expr.Pos(), kind: kind}
+	args := expr.Args
+	if len(args) == 0 {
+		return nil, p.newError(expr, "Invalid ginkgo spec %s", getCallName(expr))
+	}
+	name, err := p.evalString(args[0])
+	if err != nil {
+		return nil, p.newError(args[0], "Unsupported testcase description %s: %v", types.ExprString(args[0]), err)
+	}
+	testcaseSpec.name = name
+	if err := p.parseDecorators(testcaseSpec, args[1:]); err != nil {
+		return nil, err
+	}
+
+	if ginkgoUtil.ElementIsInSlice(kind, subjectNodes) {
+		// leaf node
+		testcaseSpec.leaf = true
+		if !hasBody(args[1:]) {
+			// ginkgo v2中没有函数体的用例会被标记为pending
+			testcaseSpec.markFocusAndPending(false, true)
+		}
 		return testcaseSpec, nil
 	}
-	return nil, nil
+	body := p.findBody(args[1:])
+	if body == nil {
+		return nil, p.newError(expr, "Invalid ginkgo spec %s", getCallName(expr))
+	}
+	testcaseSpec.subSpecs = p.parseStmts(body.List)
+	return testcaseSpec, nil
 }
 
-func ParseTestCaseInFile(projPath string, path string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+func hasBody(args []ast.Expr) bool {
+	for _, arg := range args {
+		if !isDecorator(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFile 解析文件中通过包级变量声明的顶层用例节点
+func (p *specParser) parseFile(file *ast.File) []*TestCaseSpec {
+	var specs []*TestCaseSpec
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			for _, value := range spec.(*ast.ValueSpec).Values {
+				if call, ok := value.(*ast.CallExpr); ok {
+					specs = append(specs, p.parseCall(call)...)
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// parseTestFiles 按文件名顺序解析包内所有测试文件中的顶层用例节点
+func (p *specParser) parseTestFiles() []*TestCaseSpec {
+	var specs []*TestCaseSpec
+	for _, filename := range p.pkg.filenames {
+		if strings.HasSuffix(filename, "_test.go") {
+			specs = append(specs, p.parseFile(p.pkg.files[filename])...)
+		}
+	}
+	return specs
+}
+
+// genTestCases 根据顶层用例节点生成用例并记录ginkgo版本，filter不为空时仅保留满足条件的用例
+func (p *specParser) genTestCases(specs []*TestCaseSpec, ginkgoVersion int, filter func(spec *TestCaseSpec, testcase *ginkgoTestcase.TestCase) bool) []*ginkgoTestcase.TestCase {
 	var testcaseList []*ginkgoTestcase.TestCase
+	for _, spec := range specs {
+		for _, testcase := range p.genTestCaseBySpec(spec, nil) {
+			if filter != nil && !filter(spec, testcase) {
+				continue
+			}
+			testcase.Attributes["ginkgoVersion"] = strconv.Itoa(ginkgoVersion)
+			testcaseList = append(testcaseList, testcase)
+		}
+	}
+	return testcaseList
+}
+
+// ParseTestCaseInPackage 静态解析目录对应包中的所有用例，包内辅助函数可以声明在任意文件中
+func ParseTestCaseInPackage(projPath string, dir string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
+	log.Println("Parse testcase in package", dir)
+	pkg, err := loadStaticPackage(dir)
+	if err != nil {
+		log.Printf("load package %s failed, err: %s", dir, err.Error())
+		return nil, []*sdkModel.LoadError{
+			{
+				Name:    dir,
+				Message: err.Error(),
+			},
+		}
+	}
+	ginkgoVersion := pkg.ginkgoVersion()
+	if ginkgoVersion == 0 {
+		return nil, nil
+	}
+	p := &specParser{projPath: projPath, pkg: pkg}
+	specs := p.parseTestFiles()
+	return p.genTestCases(specs, ginkgoVersion, nil), p.loadErrors
+}
+
+// ParseTestCaseInFile 静态解析文件中的用例，返回叶子节点位于该文件或顶层容器声明在该文件中的用例
+func ParseTestCaseInFile(projPath string, path string) ([]*ginkgoTestcase.TestCase, []*sdkModel.LoadError) {
 	var loadErrors []*sdkModel.LoadError
 	if !strings.HasSuffix(path, "_test.go") {
 		return nil, nil
 	}
 	log.Println("Parse testcase in file", path)
-	code, err := os.ReadFile(path)
+	pkg, err := loadStaticPackage(filepath.Dir(path))
 	if err != nil {
-		log.Printf("failed to read file %s", path)
+		log.Printf("load package of file %s failed, err: %s", path, err.Error())
 		loadErrors = append(loadErrors, &sdkModel.LoadError{
 			Name:    path,
 			Message: err.Error(),
 		})
 		return nil, loadErrors
 	}
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, path, code, 0)
-	if err != nil {
-		log.Printf("parse file %s failed, err: %s", path, err.Error())
-		loadErrors = append(loadErrors, &sdkModel.LoadError{
-			Name:    path,
-			Message: err.Error(),
-		})
-		return nil, loadErrors
+	file, ok := pkg.files[path]
+	if !ok {
+		// 文件不满足构建约束时不会参与类型检查，此时单独解析该文件
+		file, err = parser.ParseFile(pkg.fset, path, nil, 0)
+		if err != nil {
+			log.Printf("parse file %s failed, err: %s", path, err.Error())
+			loadErrors = append(loadErrors, &sdkModel.LoadError{
+				Name:    path,
+				Message: err.Error(),
+			})
+			return nil, loadErrors
+		}
+		pkg.files = map[string]*ast.File{path: file}
+		pkg.filenames = []string{path}
 	}
-	ginkgoVersion := 0
-	for _, decl := range file.Decls {
-		switch declType := decl.(type) {
-		case *ast.GenDecl:
-			if declType.Tok == token.IMPORT {
-				for _, spec := range declType.Specs {
-					importValue := spec.(*ast.ImportSpec).Path.Value
-					if strings.Contains(importValue, "github.com/onsi/ginkgo/v2") {
-						ginkgoVersion = 2
-					} else if strings.Contains(importValue, "github.com/onsi/ginkgo") {
-						ginkgoVersion = 1
-					}
-				}
-			} else if declType.Tok == token.VAR {
-				for _, spec := range declType.Specs {
-					for _, value := range spec.(*ast.ValueSpec).Values {
-						switch value := value.(type) {
-						case *ast.CallExpr:
-							spec, err := parseTestCaseSpec(value)
-							if err != nil {
-								log.Printf("Static parse ginkgo testcase in %s failed: %v", path, err)
-								loadErrors = append(loadErrors, &sdkModel.LoadError{
-									Name:    path,
-									Message: err.Error(),
-								})
-								continue
-							}
-							if spec != nil {
-								testcases, err := genTestCaseBySpec(path[len(projPath)+1:], spec, nil)
-								if err != nil {
-									log.Printf("Generate testcase failed: %v", err)
-									return testcaseList, loadErrors
-								}
-								if ginkgoVersion == 0 {
-									log.Printf("ginkgo version was not found, Please check file import")
-									return testcaseList, loadErrors
-								}
-								for _, testcase := range testcases {
-									testcase.Attributes["ginkgoVersion"] = strconv.Itoa(ginkgoVersion)
-								}
-								testcaseList = append(testcaseList, testcases...)
-							}
-						}
-					}
-				}
-			}
+	ginkgoVersion := pkg.ginkgoVersion()
+	if ginkgoVersion == 0 {
+		log.Printf("ginkgo version was not found, Please check file import")
+		return nil, nil
+	}
+	p := &specParser{projPath: projPath, pkg: pkg}
+	specs := p.parseTestFiles()
+	relPath := p.relPath(file.Pos())
+	testcaseList := p.genTestCases(specs, ginkgoVersion, func(spec *TestCaseSpec, testcase *ginkgoTestcase.TestCase) bool {
+		return testcase.Path == relPath || p.relPath(spec.pos) == relPath
+	})
+	// 仅上报当前文件中的解析错误，其他文件的错误在加载对应文件时上报
+	for _, loadError := range p.loadErrors {
+		if strings.HasPrefix(loadError.Name, relPath+":") {
+			loadErrors = append(loadErrors, loadError)
 		}
 	}
 	return testcaseList, loadErrors
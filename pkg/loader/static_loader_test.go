@@ -0,0 +1,500 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, projPath, name, content string) string {
+	path := filepath.Join(projPath, name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+	return path
+}
+
+func TestParseTableTestCase(t *testing.T) {
+	projPath := t.TempDir()
+	path := writeTestFile(t, projPath, "table/table_test.go", `package table
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Calculator", func() {
+	DescribeTable("add",
+		func(m, n, expected int) {},
+		Entry("small numbers", 1, 2, 3),
+		Entry(nil, 10, -20, -10),
+		Entry(EntryDescription("%d plus %d equals %d"), 3, 4, 7),
+		Entry(nil, Label("label01"), 1.5, 1, 2.5),
+	)
+	DescribeTable("compare",
+		func(m, n int, expected bool) {},
+		EntryDescription("%d > %d is %t"),
+		[]TableEntry{
+			Entry(nil, 2, 1, true),
+			Entry("equal", 1, 1, false),
+		},
+	)
+	DescribeTable("description func",
+		func(s string) {},
+		func(s string) string { return s },
+		Entry(nil, "unsupported"),
+		Entry("supported", "value"),
+	)
+	DescribeTableSubtree("divide",
+		func(m, n int) {
+			It("divides", func() {})
+			Context("sign", func() {
+				It("keeps sign", func() {})
+			})
+		},
+		Entry("by one", 5, 1),
+		Entry(nil, 9, 3),
+	)
+})
+`)
+	testcases, loadErrors := ParseTestCaseInFile(projPath, path)
+	// 表格级别的描述函数无法静态计算，对应的Entry需要上报加载错误
+	assert.Len(t, loadErrors, 1)
+	assert.Equal(t, "table/table_test.go:26", loadErrors[0].Name)
+	var names []string
+	for _, testcase := range testcases {
+		assert.Equal(t, "table/table_test.go", testcase.Path)
+		names = append(names, testcase.Name)
+	}
+	assert.Equal(t, []string{
+		"Calculator add small numbers",
+		"Calculator add Entry: 10, -20, -10",
+		"Calculator add 3 plus 4 equals 7",
+		"Calculator add Entry: 1.5, 1, 2.5 [label01]",
+		"Calculator compare 2 > 1 is true",
+		"Calculator compare equal",
+		"Calculator description func supported",
+		"Calculator divide by one divides",
+		"Calculator divide by one sign keeps sign",
+		"Calculator divide Entry: 9, 3 divides",
+		"Calculator divide Entry: 9, 3 sign keeps sign",
+	}, names)
+}
+
+func loadSelectors(t *testing.T, projPath, selectorPath, parseMode string) []string {
+	defer os.Setenv("TESTSOLAR_TTP_PARSEMODE", os.Getenv("TESTSOLAR_TTP_PARSEMODE"))
+	err := os.Setenv("TESTSOLAR_TTP_PARSEMODE", parseMode)
+	assert.NoError(t, err)
+	testcases, loadErrors := LoadTestCase(projPath, selectorPath)
+	assert.Len(t, loadErrors, 0)
+	var selectors []string
+	for _, testcase := range testcases {
+		selectors = append(selectors, testcase.GetSelector())
+	}
+	sort.Strings(selectors)
+	return selectors
+}
+
+func TestStaticAndDynamicLoadTableTestCase(t *testing.T) {
+	projPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	staticSelectors := loadSelectors(t, projPath, "table", "static")
+	defer os.Remove("../../testdata/table.test")
+	defer os.Remove("../../testdata/report.json")
+	dynamicSelectors := loadSelectors(t, projPath, "table", "dynamic")
+	assert.Len(t, staticSelectors, 10)
+	assert.Equal(t, dynamicSelectors, staticSelectors)
+}
+
+func TestStaticAndDynamicLoadLocation(t *testing.T) {
+	projPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	defer os.Setenv("TESTSOLAR_TTP_PARSEMODE", os.Getenv("TESTSOLAR_TTP_PARSEMODE"))
+	loadLocations := func(parseMode string) map[string]string {
+		err := os.Setenv("TESTSOLAR_TTP_PARSEMODE", parseMode)
+		assert.NoError(t, err)
+		locations := map[string]string{}
+		for _, selectorPath := range []string{"table", "shared"} {
+			testcases, loadErrors := LoadTestCase(projPath, selectorPath)
+			assert.Len(t, loadErrors, 0)
+			for _, testcase := range testcases {
+				locations[testcase.GetSelector()] = strings.Join([]string{
+					testcase.Attributes["file"],
+					testcase.Attributes["line"],
+					testcase.Attributes["column"],
+					testcase.Attributes["containerLocations"],
+				}, " ")
+			}
+		}
+		return locations
+	}
+	staticLocations := loadLocations("static")
+	defer os.Remove("../../testdata/table.test")
+	defer os.Remove("../../testdata/shared.test")
+	defer os.Remove("../../testdata/report.json")
+	dynamicLocations := loadLocations("dynamic")
+	assert.Len(t, staticLocations, 18)
+	assert.Equal(t, dynamicLocations, staticLocations)
+	assert.Equal(t, `shared/behaviors_test.go 16 3 ["shared/cache_test.go:9","shared/cache_test.go:13","shared/behaviors_test.go:15"]`,
+		staticLocations["shared/behaviors_test.go?Cache disk when full evicts 8 entries"])
+}
+
+func TestStaticAndDynamicLoadSharedTestCase(t *testing.T) {
+	projPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	staticSelectors := loadSelectors(t, projPath, "shared", "static")
+	defer os.Remove("../../testdata/shared.test")
+	defer os.Remove("../../testdata/report.json")
+	dynamicSelectors := loadSelectors(t, projPath, "shared", "dynamic")
+	assert.Len(t, staticSelectors, 8)
+	assert.Equal(t, dynamicSelectors, staticSelectors)
+}
+
+func TestParseTestCaseDecorators(t *testing.T) {
+	projPath := t.TempDir()
+	path := writeTestFile(t, projPath, "decorator/decorator_test.go", `package decorator
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Decorator", Label("suite", "owner:tom"), Ordered, FlakeAttempts(3), func() {
+	Context("context", Serial, Label("context"), func() {
+		It("labelled", Label("description:demo test", "suite"), NodeTimeout(10*time.Second), func(ctx SpecContext) {})
+		It("repeated", MustPassRepeatedly(2), SpecTimeout(time.Minute+30*time.Second), FlakeAttempts(1), func() {})
+	})
+	It("plain", func() {})
+})
+`)
+	testcases, loadErrors := ParseTestCaseInFile(projPath, path)
+	assert.Len(t, loadErrors, 0)
+	assert.Len(t, testcases, 3)
+
+	labelled := testcases[0]
+	assert.Equal(t, "Decorator context labelled [suite, owner:tom, context, description:demo test]", labelled.Name)
+	assert.Equal(t, `["suite","owner:tom","context","description:demo test"]`, labelled.Attributes["label"])
+	assert.Equal(t, labelled.Attributes["label"], labelled.Attributes["tags"])
+	assert.Equal(t, "tom", labelled.Attributes["owner"])
+	assert.Equal(t, "demo test", labelled.Attributes["description"])
+	assert.Equal(t, `["Decorator context","labelled [suite, owner:tom, context, description:demo test]"]`, labelled.Attributes["nameList"])
+	assert.Equal(t, "Decorator context labelled", labelled.Attributes["testsolar_requests_key"])
+	assert.Equal(t, "true", labelled.Attributes["ordered"])
+	assert.Equal(t, "true", labelled.Attributes["serial"])
+	assert.Equal(t, "3", labelled.Attributes["flakeAttempts"])
+	assert.Equal(t, "10s", labelled.Attributes["nodeTimeout"])
+	assert.Equal(t, "2", labelled.Attributes["ginkgoVersion"])
+
+	repeated := testcases[1]
+	assert.Equal(t, "2", repeated.Attributes["mustPassRepeatedly"])
+	assert.Equal(t, "1m30s", repeated.Attributes["specTimeout"])
+	assert.Equal(t, "1", repeated.Attributes["flakeAttempts"])
+
+	plain := testcases[2]
+	assert.Equal(t, "Decorator plain [suite, owner:tom]", plain.Name)
+	assert.Equal(t, "true", plain.Attributes["ordered"])
+	_, ok := plain.Attributes["serial"]
+	assert.False(t, ok)
+
+	defer os.Setenv("TESTSOLAR_TTP_WITHOUTLABELS", os.Getenv("TESTSOLAR_TTP_WITHOUTLABELS"))
+	err := os.Setenv("TESTSOLAR_TTP_WITHOUTLABELS", "true")
+	assert.NoError(t, err)
+	testcases, _ = ParseTestCaseInFile(projPath, path)
+	assert.Equal(t, "Decorator plain", testcases[2].Name)
+}
+
+func TestParseTestCaseNodeVariants(t *testing.T) {
+	projPath := t.TempDir()
+	path := writeTestFile(t, projPath, "variant/variant_test.go", `package variant
+
+import (
+	g "github.com/onsi/ginkgo/v2"
+)
+
+var _ = g.Describe("Variant", func() {
+	g.When("when", func() {
+		g.Specify("specify", func() {})
+		g.FIt("focused it", func() {})
+		g.XIt("pending it", func() {})
+		g.It("no body")
+	})
+	g.FContext("focused context", func() {
+		g.It("it", func() {})
+		g.PSpecify("pending specify", func() {})
+	})
+	g.PDescribe("pending describe", func() {
+		g.It("it", func() {})
+	})
+	g.FDescribeTable("focused table",
+		func(n int) {},
+		g.Entry("entry", 1),
+		g.XEntry("pending entry", 2),
+	)
+	g.XWhen("pending when", func() {
+		g.FEntry("entry outside table", 1)
+	})
+})
+
+var _ = g.FWhen("top level", func() {
+	g.PIt("pending it", func() {})
+})
+`)
+	testcases, loadErrors := ParseTestCaseInFile(projPath, path)
+	assert.Len(t, loadErrors, 0)
+	type expected struct {
+		name    string
+		focused string
+		pending string
+	}
+	var results []expected
+	for _, testcase := range testcases {
+		results = append(results, expected{testcase.Name, testcase.Attributes["focused"], testcase.Attributes["pending"]})
+	}
+	assert.Equal(t, []expected{
+		{"Variant when specify", "", ""},
+		{"Variant when focused it", "true", ""},
+		{"Variant when pending it", "", "true"},
+		{"Variant when no body", "", "true"},
+		{"Variant focused context it", "true", ""},
+		{"Variant focused context pending specify", "true", "true"},
+		{"Variant pending describe it", "", "true"},
+		{"Variant focused table entry", "true", ""},
+		{"Variant focused table pending entry", "true", "true"},
+		{"top level pending it", "true", "true"},
+	}, results)
+}
+
+func TestParseTestCaseConstantDescription(t *testing.T) {
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "go.mod", "module example.com/demo\n")
+	writeTestFile(t, projPath, "constant/constant.go", `package constant
+
+const Component = "cache"
+`)
+	writeTestFile(t, projPath, "constant/constant_internal_test.go", `package constant
+
+const (
+	prefix  = "[" + Component + "]"
+	retries = 3
+)
+`)
+	path := writeTestFile(t, projPath, "constant/constant_test.go", `package constant_test
+
+import (
+	"fmt"
+	format "fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+
+	"example.com/demo/constant"
+)
+
+const suiteName = "Constant " + constant.Component
+
+var name = "variable"
+
+type formatter struct{}
+
+func (formatter) Sprintf(format string, args ...interface{}) string { return "" }
+
+var _ = Describe(suiteName, Label(constant.Component), func() {
+	It(fmt.Sprintf("retries %d times", 3), func() {})
+	It(fmt.Sprintf("%s with %s", suiteName, "suffix")+"!", func() {})
+	It(name, func() {})
+	Context(name, func() {
+		It("hidden", func() {})
+	})
+	DescribeTable("table",
+		func(s string) {},
+		Entry(constant.Component, name),
+		Entry(nil, name),
+	)
+	It(format.Sprintf("aliased %d", 1), func() {})
+	Context("shadowed", func() {
+		fmt := formatter{}
+		It(fmt.Sprintf("local %d", 1), func() {})
+	})
+})
+`)
+	testcases, loadErrors := ParseTestCaseInFile(projPath, path)
+	var names []string
+	for _, testcase := range testcases {
+		names = append(names, testcase.Name)
+	}
+	assert.Equal(t, []string{
+		"Constant cache retries 3 times [cache]",
+		"Constant cache Constant cache with suffix! [cache]",
+		"Constant cache table cache [cache]",
+		"Constant cache aliased 1 [cache]",
+	}, names)
+	var errorNames []string
+	for _, loadError := range loadErrors {
+		errorNames = append(errorNames, loadError.Name)
+	}
+	assert.Equal(t, []string{
+		"constant/constant_test.go:23",
+		"constant/constant_test.go:24",
+		"constant/constant_test.go:30",
+		// 与fmt同名的局部变量不会按fmt.Sprintf计算
+		"constant/constant_test.go:35",
+	}, errorNames)
+}
+
+func TestParseTestCaseHelperFunctions(t *testing.T) {
+	projPath := t.TempDir()
+	writeTestFile(t, projPath, "helper/helper_test.go", `package helper
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func ItBehavesLikeStore(name string) {
+	It("saves "+name, func() {})
+	ItBehavesLikeStore(name)
+}
+
+func storeBody() {
+	It("loads", func() {})
+}
+`)
+	path := writeTestFile(t, projPath, "helper/store_test.go", `package helper
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var stores = []string{"redis", "etcd"}
+
+var ports = map[string]int{"redis": 6379, "etcd": 2379}
+
+var _ = Describe("Store", func() {
+	ItBehavesLikeStore("memory")
+	Context("body", storeBody)
+	for index, name := range stores {
+		It(name, Label(fmt.Sprint(index)), func() {})
+	}
+	for name := range ports {
+		It(name, func() {})
+	}
+	for i := range 2 {
+		It(fmt.Sprintf("round %d", i), func() {})
+	}
+	for _, name := range loadNames() {
+		It(name, func() {})
+	}
+})
+`)
+	testcases, loadErrors := ParseTestCaseInFile(projPath, path)
+	// 无法展开的循环以及遍历map的循环中依赖循环变量的用例描述会上报加载错误
+	var errorNames []string
+	for _, loadError := range loadErrors {
+		errorNames = append(errorNames, loadError.Name)
+	}
+	assert.Equal(t, []string{"helper/store_test.go:20", "helper/store_test.go:26"}, errorNames)
+	type expected struct {
+		path string
+		name string
+	}
+	var results []expected
+	for _, testcase := range testcases {
+		results = append(results, expected{testcase.Path, testcase.Name})
+	}
+	assert.Equal(t, []expected{
+		{"helper/helper_test.go", "Store saves memory"},
+		{"helper/helper_test.go", "Store body loads"},
+		{"helper/store_test.go", "Store redis [0]"},
+		{"helper/store_test.go", "Store etcd [1]"},
+		{"helper/store_test.go", "Store round 0"},
+		{"helper/store_test.go", "Store round 1"},
+	}, results)
+
+	// 辅助函数所在的文件中没有声明顶层容器，因此只返回叶子节点位于该文件中的用例
+	testcases, _ = ParseTestCaseInFile(projPath, filepath.Join(projPath, "helper/helper_test.go"))
+	assert.Len(t, testcases, 2)
+	testcases, _ = ParseTestCaseInPackage(projPath, filepath.Join(projPath, "helper"))
+	assert.Len(t, testcases, 6)
+}
+
+func TestParseTestCaseControlFlow(t *testing.T) {
+	projPath := t.TempDir()
+	path := writeTestFile(t, projPath, "flow/flow_test.go", `package flow
+
+import (
+	"fmt"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+const debug = false
+
+var _ = Describe("Flow", func() {
+	if os.Getenv("SLOW") != "" {
+		It("slow", func() {})
+	} else if os.Getenv("FAST") != "" {
+		Context("fast", func() {
+			It("quick", func() {})
+		})
+	}
+	if debug {
+		It("debug", func() {})
+	}
+	{
+		It("block", func() {})
+	}
+	switch os.Getenv("MODE") {
+	case "a":
+		It("mode a", func() {})
+	default:
+		It("mode default", func() {})
+	}
+	for i := 0; i < 2; i++ {
+		It("loop", func() {})
+	}
+	for _, name := range os.Args {
+		It(name, func() {})
+		It("static in range", func() {})
+	}
+	for _, size := range []int{1, 2} {
+		It(fmt.Sprintf("size %d", size), func() {})
+	}
+	func() {
+		It("immediate", func() {})
+	}()
+	_ = os.Getenv("IGNORED")
+	_ = It("assigned", func() {})
+	os.Getenv("CALL")
+})
+`)
+	testcases, loadErrors := ParseTestCaseInFile(projPath, path)
+	assert.Len(t, loadErrors, 1)
+	assert.Equal(t, "flow/flow_test.go:36", loadErrors[0].Name)
+	type expected struct {
+		name        string
+		conditional string
+	}
+	var results []expected
+	for _, testcase := range testcases {
+		results = append(results, expected{testcase.Name, testcase.Attributes["conditional"]})
+	}
+	assert.Equal(t, []expected{
+		{"Flow slow", "true"},
+		{"Flow fast quick", "true"},
+		{"Flow block", ""},
+		{"Flow mode a", "true"},
+		{"Flow mode default", "true"},
+		{"Flow loop", "true"},
+		{"Flow static in range", "true"},
+		{"Flow size 1", ""},
+		{"Flow size 2", ""},
+		{"Flow immediate", ""},
+		{"Flow assigned", ""},
+	}, results)
+}
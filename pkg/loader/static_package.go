@@ -0,0 +1,157 @@
+package loader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	"github.com/pkg/errors"
+)
+
+// staticPackage 静态解析的包，包含目录下所有go文件的语法树以及类型检查结果
+// 类型检查用于计算用例描述中的常量表达式以及定位包内的辅助函数，检查过程中产生的错误(例如无法导入依赖包)会被忽略
+type staticPackage struct {
+	dir       string
+	fset      *token.FileSet
+	files     map[string]*ast.File
+	filenames []string
+	info      *types.Info
+	// funcDecls 包内声明的函数，用于内联在容器中调用的辅助函数
+	funcDecls map[types.Object]*ast.FuncDecl
+	// varValues 包级变量的初始值，用于展开遍历字面量集合的循环
+	varValues map[types.Object]ast.Expr
+}
+
+// staticImporter 仅提供当前目录下已完成类型检查的包，外部测试包(xxx_test)可以借此引用被测包中的常量
+type staticImporter struct {
+	packages map[string]*types.Package
+}
+
+func (i *staticImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := i.packages[importPath]; ok {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %s is not available during static parsing", importPath)
+}
+
+func loadStaticPackage(dir string) (*staticPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read dir %s", dir)
+	}
+	pkg := &staticPackage{
+		dir:   dir,
+		fset:  token.NewFileSet(),
+		files: map[string]*ast.File{},
+		info: &types.Info{
+			Types: map[ast.Expr]types.TypeAndValue{},
+			Defs:  map[*ast.Ident]types.Object{},
+			Uses:  map[*ast.Ident]types.Object{},
+		},
+		funcDecls: map[types.Object]*ast.FuncDecl{},
+		varValues: map[types.Object]ast.Expr{},
+	}
+	groups := map[string][]*ast.File{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, entry.Name()); err != nil || !match {
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+		file, err := parser.ParseFile(pkg.fset, filePath, nil, 0)
+		if err != nil {
+			log.Printf("parse file %s failed, err: %v", filePath, err)
+			continue
+		}
+		pkg.files[filePath] = file
+		pkg.filenames = append(pkg.filenames, filePath)
+		groups[file.Name.Name] = append(groups[file.Name.Name], file)
+	}
+	importer := &staticImporter{packages: map[string]*types.Package{}}
+	importPath := dir
+	if modRoot, modPath, err := ginkgoUtil.FindGoModule(dir); err == nil {
+		if relPath, err := filepath.Rel(modRoot, dir); err == nil {
+			importPath = path.Join(modPath, filepath.ToSlash(relPath))
+		}
+	}
+	// 先检查被测包再检查外部测试包，保证外部测试包可以导入被测包
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return !strings.HasSuffix(names[i], "_test") && strings.HasSuffix(names[j], "_test")
+	})
+	for _, name := range names {
+		conf := types.Config{
+			Importer: importer,
+			Error:    func(err error) {},
+		}
+		checked, _ := conf.Check(importPath, pkg.fset, groups[name], pkg.info)
+		if checked != nil && !strings.HasSuffix(name, "_test") {
+			importer.packages[importPath] = checked
+		}
+	}
+	pkg.indexDecls()
+	return pkg, nil
+}
+
+// indexDecls 记录包内的函数声明以及包级变量的初始值
+func (pkg *staticPackage) indexDecls() {
+	for _, filename := range pkg.filenames {
+		for _, decl := range pkg.files[filename].Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv != nil || decl.Body == nil {
+					continue
+				}
+				if obj := pkg.info.Defs[decl.Name]; obj != nil {
+					pkg.funcDecls[obj] = decl
+				}
+			case *ast.GenDecl:
+				if decl.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					valueSpec, ok := spec.(*ast.ValueSpec)
+					if !ok || len(valueSpec.Names) != len(valueSpec.Values) {
+						continue
+					}
+					for i, name := range valueSpec.Names {
+						if obj := pkg.info.Defs[name]; obj != nil {
+							pkg.varValues[obj] = valueSpec.Values[i]
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// ginkgoVersion 根据包内文件的导入路径判断使用的ginkgo版本，未导入ginkgo时返回0
+func (pkg *staticPackage) ginkgoVersion() int {
+	version := 0
+	for _, file := range pkg.files {
+		for _, spec := range file.Imports {
+			if strings.Contains(spec.Path.Value, "github.com/onsi/ginkgo/v2") {
+				return 2
+			} else if strings.Contains(spec.Path.Value, "github.com/onsi/ginkgo") {
+				version = 1
+			}
+		}
+	}
+	return version
+}
@@ -0,0 +1,29 @@
+package loader
+
+import (
+	"path/filepath"
+
+	ginkgoResult "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/result"
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+)
+
+// genTestifyCases 为testify测试套中的每个测试方法生成用例，用例名为`MySuite/TestCreate`，路径为方法所在的文件
+func genTestifyCases(projPath string, dir string, suites []*ginkgoUtil.TestifySuite) []*ginkgoTestcase.TestCase {
+	var testcases []*ginkgoTestcase.TestCase
+	for _, suite := range suites {
+		for _, method := range suite.Methods {
+			path := relPackagePath(projPath, filepath.Join(dir, method.File))
+			attributes := ginkgoResult.GenLocationAttributes(path, method.Line, method.Column, nil)
+			attributes["framework"] = ginkgoTestcase.FrameworkTestify
+			attributes["suite"] = suite.Name
+			attributes["suiteEntry"] = suite.Entry
+			testcases = append(testcases, &ginkgoTestcase.TestCase{
+				Path:       path,
+				Name:       suite.Name + "/" + method.Name,
+				Attributes: attributes,
+			})
+		}
+	}
+	return testcases
+}
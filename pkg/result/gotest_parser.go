@@ -0,0 +1,218 @@
+package result
+
+import (
+	"bufio"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+)
+
+// GoTestEvent `go test -json`以及`go tool test2json`输出的单个事件
+type GoTestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Output  string    `json:"Output"`
+	Elapsed float64   `json:"Elapsed"`
+}
+
+// goTestRecord 单个测试(包括子测试)在执行过程中的状态
+type goTestRecord struct {
+	result *sdkModel.TestResult
+	logs   []*sdkModel.TestCaseLog
+	done   bool
+}
+
+// maxGoTestMessageLength 失败信息的最大长度，完整输出保存在日志中
+const maxGoTestMessageLength = 512
+
+// ParseGoTestEvents 解析`go test -json`/`test2json`的输出，每个测试(包括子测试)生成一个测试结果
+// caseName根据go test输出的测试名(例如`TestAdd/zero`)生成`path?name`形式的用例名，未开始执行的测试不会生成结果
+func ParseGoTestEvents(output string, caseName func(testName string) string, attributes map[string]string) []*sdkModel.TestResult {
+	var events []GoTestEvent
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event GoTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Action == "" {
+			// 非json格式的行通常为测试二进制在启动阶段的输出
+			events = append(events, GoTestEvent{Output: scanner.Text()})
+			continue
+		}
+		events = append(events, event)
+	}
+	return parseGoTestEvents(events, caseName, attributes)
+}
+
+// goTestReportRegex 匹配`-test.v`输出中测试结束时的状态行，例如`    --- FAIL: TestAdd/zero (0.00s)`
+var goTestReportRegex = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+) \([0-9.]+s\)`)
+
+// goTestStartRegex 匹配`-test.v`输出中测试开始或者切换输出归属的行，例如`=== RUN   TestAdd`、`=== NAME  TestAdd`
+var goTestStartRegex = regexp.MustCompile(`^=== (RUN|PAUSE|CONT|NAME)\s+(\S+)`)
+
+// ParseGoTestVerboseOutput 解析测试二进制`-test.v`参数的文本输出，用于执行环境中没有go工具链、无法通过test2json转换输出的场景
+// 与test2json的转换规则一致，`=== RUN`之后以及`--- FAIL`之后缩进的输出归属于对应的测试，其他输出归属于包
+func ParseGoTestVerboseOutput(output string, caseName func(testName string) string, attributes map[string]string) []*sdkModel.TestResult {
+	var events []GoTestEvent
+	var current string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		now := time.Now()
+		if match := goTestStartRegex.FindStringSubmatch(line); match != nil {
+			current = match[2]
+			if match[1] == "RUN" {
+				events = append(events, GoTestEvent{Time: now, Action: "run", Test: current})
+			}
+			continue
+		}
+		if match := goTestReportRegex.FindStringSubmatch(line); match != nil {
+			current = match[2]
+			events = append(events, GoTestEvent{Time: now, Action: strings.ToLower(match[1]), Test: current})
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "FAIL" || trimmed == "PASS" || strings.HasPrefix(trimmed, "coverage: ") {
+			current = ""
+			if trimmed == "FAIL" {
+				events = append(events, GoTestEvent{Time: now, Action: "fail"})
+			}
+			continue
+		}
+		events = append(events, GoTestEvent{Time: now, Action: "output", Test: current, Output: line + "\n"})
+	}
+	return parseGoTestEvents(events, caseName, attributes)
+}
+
+// parseGoTestEvents 根据事件生成测试结果，Action为空的事件表示无法解析为事件的原始输出行
+func parseGoTestEvents(events []GoTestEvent, caseName func(testName string) string, attributes map[string]string) []*sdkModel.TestResult {
+	var records []*goTestRecord
+	running := map[string]*goTestRecord{}
+	var packageLogs []*sdkModel.TestCaseLog
+	var packageFailed bool
+	var lastTime time.Time
+	for _, event := range events {
+		if event.Action == "" {
+			if line := strings.TrimSpace(event.Output); line != "" {
+				packageLogs = append(packageLogs, &sdkModel.TestCaseLog{Time: lastTime, Level: sdkModel.LogLevelInfo, Content: line})
+			}
+			continue
+		}
+		if event.Time.IsZero() {
+			event.Time = time.Now()
+		}
+		lastTime = event.Time
+		if event.Test == "" {
+			switch event.Action {
+			case "output":
+				packageLogs = appendGoTestOutput(packageLogs, event)
+			case "fail":
+				packageFailed = true
+			}
+			continue
+		}
+		record, ok := running[event.Test]
+		if !ok {
+			if event.Action != "run" && event.Action != "output" {
+				continue
+			}
+			testAttributes := map[string]string{}
+			for k, v := range attributes {
+				testAttributes[k] = v
+			}
+			record = &goTestRecord{
+				result: &sdkModel.TestResult{
+					Test: &sdkModel.TestCase{
+						Name:       caseName(event.Test),
+						Attributes: testAttributes,
+					},
+					StartTime:  event.Time,
+					EndTime:    event.Time,
+					ResultType: sdkModel.ResultTypeRunning,
+				},
+			}
+			running[event.Test] = record
+			records = append(records, record)
+		}
+		switch event.Action {
+		case "output":
+			record.logs = appendGoTestOutput(record.logs, event)
+		case "pass", "fail", "skip":
+			record.done = true
+			record.result.EndTime = event.Time
+			record.result.ResultType = goTestResultType(event.Action)
+		}
+	}
+	var results []*sdkModel.TestResult
+	for _, record := range records {
+		result := record.result
+		logs := record.logs
+		if !record.done {
+			// 测试没有结束事件，通常是测试二进制panic或者超时退出，此时使用包级别的输出作为失败原因
+			result.ResultType = sdkModel.ResultTypeFailed
+			result.EndTime = lastTime
+			logs = append(logs, packageLogs...)
+		} else if packageFailed && result.ResultType == sdkModel.ResultTypeFailed && len(logs) == 0 {
+			logs = append(logs, packageLogs...)
+		}
+		if result.ResultType == sdkModel.ResultTypeFailed {
+			var contents []string
+			for _, l := range logs {
+				contents = append(contents, l.Content)
+				l.Level = sdkModel.LogLevelError
+			}
+			result.Message = ginkgoUtil.ShortenString(strings.Join(contents, "\n"), maxGoTestMessageLength)
+		}
+		result.Steps = []*sdkModel.TestCaseStep{
+			{
+				Title:      result.Test.Name,
+				StartTime:  result.StartTime,
+				EndTime:    result.EndTime,
+				ResultType: result.ResultType,
+				Logs:       logs,
+			},
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// appendGoTestOutput 追加测试输出，`=== RUN`、`--- FAIL`等由testing包输出的状态行不作为日志
+func appendGoTestOutput(logs []*sdkModel.TestCaseLog, event GoTestEvent) []*sdkModel.TestCaseLog {
+	content := strings.TrimRight(event.Output, "\n")
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" || strings.HasPrefix(trimmed, "=== ") {
+		return logs
+	}
+	if trimmed == "PASS" || trimmed == "FAIL" {
+		return logs
+	}
+	for _, prefix := range []string{"--- PASS: ", "--- FAIL: ", "--- SKIP: ", "testing: warning: no tests to run"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return logs
+		}
+	}
+	return append(logs, &sdkModel.TestCaseLog{
+		Time:    event.Time,
+		Level:   sdkModel.LogLevelInfo,
+		Content: content,
+	})
+}
+
+func goTestResultType(action string) sdkModel.ResultType {
+	switch action {
+	case "pass":
+		return sdkModel.ResultTypeSucceed
+	case "skip":
+		return sdkModel.ResultTypeIgnored
+	default:
+		return sdkModel.ResultTypeFailed
+	}
+}
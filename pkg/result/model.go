@@ -26,7 +26,8 @@ func newTestStepInfo(rawStr string) (*TestStepInfo, error) {
 }
 
 type NodeLocation struct {
-	FileName string
+	FileName   string
+	LineNumber int
 }
 
 type Value struct {
@@ -101,7 +102,7 @@ func (s *Spec) getContainerAndLeafName() (string, string) {
 		containerName = strings.Join(filteredContainerTests, " ")
 		without, _ := strconv.ParseBool(os.Getenv("TESTSOLAR_TTP_WITHOUTLABELS"))
 		if !without {
-			leafName = addLabels(s.LeafNodeText, s.ContainerHierarchyLabels, s.LeafNodeLabels)
+			leafName = AddLabels(s.LeafNodeText, s.ContainerHierarchyLabels, s.LeafNodeLabels)
 		} else {
 			leafName = s.LeafNodeText
 		}
@@ -249,6 +250,22 @@ func (s *Spec) generateFailureStep() *sdkModel.TestCaseStep {
 	return nil
 }
 
+// locationAttributes 根据叶子节点以及容器节点的位置生成用例的位置属性
+// ginkgo只记录节点所在的行，列号通过读取源码中该行第一个非空白字符的位置获得
+func (s *Spec) locationAttributes(projectPath string, columns *sourceColumns) map[string]string {
+	if s.LeafNodeLocation == nil || s.LeafNodeLocation.FileName == "" {
+		return nil
+	}
+	var containerLocations []string
+	for _, location := range s.ContainerHierarchyLocations {
+		if location != nil {
+			containerLocations = append(containerLocations, fmt.Sprintf("%s:%d", removeProjectPrefix(location.FileName, projectPath), location.LineNumber))
+		}
+	}
+	column := columns.find(s.LeafNodeLocation.FileName, s.LeafNodeLocation.LineNumber)
+	return GenLocationAttributes(removeProjectPrefix(s.LeafNodeLocation.FileName, projectPath), s.LeafNodeLocation.LineNumber, column, containerLocations)
+}
+
 func (s *Spec) outputTestName(projectPath, packPath, specName string) string {
 	casePath := removeProjectPrefix(s.LeafNodeLocation.FileName, projectPath)
 	packPath = removeProjectPrefix(packPath, projectPath)
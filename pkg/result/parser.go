@@ -91,6 +91,7 @@ func (p *ResultParser) Parse() ([]*sdkModel.TestResult, error) {
 		fmt.Print("no valid suite in results")
 		return []*sdkModel.TestResult{}, nil
 	}
+	columns := newSourceColumns()
 	for _, spec := range suite.SpecReports {
 		if !spec.isValidResultType() {
 			continue
@@ -104,26 +105,7 @@ func (p *ResultParser) Parse() ([]*sdkModel.TestResult, error) {
 			continue
 		}
 		specName := strings.Join([]string{containerName, leafName}, " ")
-		var nameList string
-		if marshalNameList, err := json.Marshal([]string{containerName, leafName}); err == nil {
-			nameList = string(marshalNameList)
-		}
-		var labelList string
-		var owner string
-		var description string
-		if labels := getLabels(spec.ContainerHierarchyLabels, spec.LeafNodeLabels); len(labels) > 0 {
-			if marshalLabelList, err := json.Marshal(labels); err == nil {
-				labelList = string(marshalLabelList)
-			}
-			for _, label := range labels {
-				if strings.HasPrefix(label, "owner:") {
-					owner = strings.TrimSpace(strings.TrimPrefix(label, "owner:"))
-				}
-				if strings.HasPrefix(label, "description:") {
-					description = strings.TrimSpace(strings.TrimPrefix(label, "description:"))
-				}
-			}
-		}
+		labels := GetLabels(spec.ContainerHierarchyLabels, spec.LeafNodeLabels)
 		steps := spec.GenerateSteps()
 		var name string
 		// 如果已经传入文件路径则直接使用文件路径作为上报用例结果的路径
@@ -132,17 +114,14 @@ func (p *ResultParser) Parse() ([]*sdkModel.TestResult, error) {
 		} else {
 			name = spec.outputTestName(p.projPath, p.packPath, specName)
 		}
+		attributes := GenCaseAttributes(containerName, leafName, spec.getSpecName(), labels)
+		for k, v := range spec.locationAttributes(p.projPath, columns) {
+			attributes[k] = v
+		}
 		testResults = append(testResults, &sdkModel.TestResult{
 			Test: &sdkModel.TestCase{
-				Name: name,
-				Attributes: map[string]string{
-					"nameList":               nameList,
-					"label":                  labelList,
-					"tags":                   labelList,
-					"owner":                  owner,
-					"description":            description,
-					"testsolar_requests_key": spec.getSpecName(),
-				},
+				Name:       name,
+				Attributes: attributes,
 			},
 			StartTime:  spec.StartTime,
 			EndTime:    spec.EndTime,
@@ -50,6 +50,7 @@ func removeExtraSpace(index int, line string) string {
 
 func ParseCaseByReg(proj string, output string, ginkgoVersion int, packPath string) ([]*ginkgoTestcase.TestCase, error) {
 	var caseList []*ginkgoTestcase.TestCase
+	columns := newSourceColumns()
 	regexPattern := `(?s).*?Will run.*?specs(.*?)Ran.*?Specs in.*?seconds`
 	re := regexp.MustCompile(regexPattern)
 	extractedText := re.FindStringSubmatch(output)
@@ -62,17 +63,19 @@ func ParseCaseByReg(proj string, output string, ginkgoVersion int, packPath stri
 			}
 			lines := getValidLines(section)
 			var path string
+			var line int
 			var nameList []string
-			pathRegex := regexp.MustCompile(`.*?(.*?):\d+`)
-			for i, line := range lines {
-				line = removeExtraSpace(i, line)
-				pathMatch := pathRegex.FindStringSubmatch(line)
-				if len(pathMatch) > 1 && strings.Contains(line, proj) {
+			pathRegex := regexp.MustCompile(`.*?(.*?):(\d+)`)
+			for i, text := range lines {
+				text = removeExtraSpace(i, text)
+				pathMatch := pathRegex.FindStringSubmatch(text)
+				if len(pathMatch) > 2 && strings.Contains(text, proj) {
 					path = pathMatch[1]
-				} else if strings.Contains(line, "•") {
+					line, _ = strconv.Atoi(pathMatch[2])
+				} else if strings.Contains(text, "•") {
 					continue
 				} else {
-					nameList = append(nameList, line)
+					nameList = append(nameList, text)
 				}
 			}
 			selectorPath, err := filepath.Rel(proj, path)
@@ -95,6 +98,9 @@ func ParseCaseByReg(proj string, output string, ginkgoVersion int, packPath stri
 			if packPath != "" {
 				caseInfo.Attributes["path"] = packPath
 			}
+			for k, v := range GenLocationAttributes(selectorPath, line, columns.find(strings.TrimSpace(path), line), nil) {
+				caseInfo.Attributes[k] = v
+			}
 			caseList = append(caseList, caseInfo)
 		}
 	}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +43,9 @@ func TestParseJsonToObj(t *testing.T) {
 	if results[0].Test.Name != "suites/demo/demo_suite_test.go?HierarchyText01 HierarchyText02   Text [label01, label02, label11, node-label01]" {
 		t.Errorf("incorrect case name: %s", results[0].Test.Name)
 	}
+	assert.Equal(t, "suites/demo/demo_suite_test.go", results[0].Test.Attributes["file"])
+	assert.Equal(t, "58", results[0].Test.Attributes["line"])
+	assert.Equal(t, `["suites/demo/demo_suite_test.go:56","suites/demo/demo_suite_test.go:57"]`, results[0].Test.Attributes["containerLocations"])
 
 	parser, err = NewResultParser("./testdata/report_with_failed_setup.json", "/data/workspace", "suites/demo", "", true)
 	assert.NoError(t, err)
@@ -65,4 +69,77 @@ func Test_parseCaseByReg(t *testing.T) {
 	cases, err := ParseCaseByReg("/data/workspace", string(byteValue), 2, "")
 	assert.NoError(t, err)
 	assert.Len(t, cases, 1)
+	assert.Equal(t, "suites/demo/demo_suite_test.go", cases[0].Attributes["file"])
+	assert.NotEmpty(t, cases[0].Attributes["line"])
+}
+
+func TestParseJUnitCases(t *testing.T) {
+	cases, err := ParseJUnitCases("./testdata/junit_dry_run.xml")
+	assert.NoError(t, err)
+	assert.Equal(t, []*JUnitCase{
+		{Name: "Testcase v1 context it"},
+		{Name: "Testcase v1 context [bracket] it"},
+		{Name: "Testcase v1 pending", Pending: true},
+	}, cases)
+	_, err = ParseJUnitCases("./testdata/not_exist.xml")
+	assert.Error(t, err)
+}
+
+func TestSourceColumns(t *testing.T) {
+	columns := newSourceColumns()
+	path := t.TempDir() + "/demo_test.go"
+	err := os.WriteFile(path, []byte("package demo\n\n\t\tIt(\"case\", func() {})\n"), 0644)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, columns.find(path, 1))
+	assert.Equal(t, 3, columns.find(path, 3))
+	assert.Equal(t, 0, columns.find(path, 10))
+	assert.Equal(t, 0, columns.find(path+".missing", 1))
+}
+
+func TestParseGoTestEvents(t *testing.T) {
+	content, err := os.ReadFile("./testdata/gotest_events.json")
+	assert.NoError(t, err)
+	caseName := func(testName string) string {
+		return "demo/plain/plain_test.go?" + testName
+	}
+	results := ParseGoTestEvents(string(content), caseName, map[string]string{"framework": "gotest"})
+	assert.Len(t, results, 5)
+	assert.Equal(t, "demo/plain/plain_test.go?TestAdd", results[0].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results[0].ResultType)
+	assert.Equal(t, "demo/plain/plain_test.go?TestAdd/zero", results[1].Test.Name)
+	assert.Equal(t, "gotest", results[1].Test.Attributes["framework"])
+	assert.Len(t, results[1].Steps[0].Logs, 1)
+	assert.Equal(t, "    plain_test.go:19: add zero", results[1].Steps[0].Logs[0].Content)
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[2].ResultType)
+	assert.Contains(t, results[2].Message, "expected 1, got 2")
+	assert.Equal(t, sdkModel.LogLevelError, results[2].Steps[0].Logs[0].Level)
+	assert.Equal(t, sdkModel.ResultTypeIgnored, results[3].ResultType)
+	// 测试二进制panic退出时，正在执行的测试没有结束事件
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[4].ResultType)
+	assert.Contains(t, results[4].Message, "panic: boom")
+}
+
+func TestParseGoTestVerboseOutput(t *testing.T) {
+	content, err := os.ReadFile("./testdata/gotest_verbose.txt")
+	assert.NoError(t, err)
+	caseName := func(testName string) string {
+		return "demo/plain/plain_test.go?" + testName
+	}
+	results := ParseGoTestVerboseOutput(string(content), caseName, map[string]string{"framework": "gotest"})
+	assert.Len(t, results, 5)
+	assert.Equal(t, "demo/plain/plain_test.go?TestAdd", results[0].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results[0].ResultType)
+	assert.Len(t, results[0].Steps[0].Logs, 0)
+	assert.Equal(t, "demo/plain/plain_test.go?TestAdd/zero", results[1].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeSucceed, results[1].ResultType)
+	assert.Equal(t, "gotest", results[1].Test.Attributes["framework"])
+	assert.Len(t, results[1].Steps[0].Logs, 1)
+	assert.Equal(t, "    plain_test.go:19: add zero", results[1].Steps[0].Logs[0].Content)
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[2].ResultType)
+	assert.Contains(t, results[2].Message, "expected 1, got 2")
+	assert.Equal(t, sdkModel.ResultTypeIgnored, results[3].ResultType)
+	// 测试二进制panic退出时，正在执行的测试没有结束状态行
+	assert.Equal(t, "demo/plain/plain_test.go?TestPanic", results[4].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[4].ResultType)
+	assert.Contains(t, results[4].Message, "panic: boom")
 }
@@ -1,13 +1,18 @@
 package result
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"os"
+	"strconv"
 	"strings"
 
 	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
 )
 
-func getLabels(hierarchyLabels [][]string, nodeLabels []string) []string {
+// GetLabels 合并容器节点与叶子节点的标签并去重
+func GetLabels(hierarchyLabels [][]string, nodeLabels []string) []string {
 	// filter duplicate labels
 	inLabels := func(labels []string, label string) bool {
 		for _, l := range labels {
@@ -34,14 +39,93 @@ func getLabels(hierarchyLabels [][]string, nodeLabels []string) []string {
 	return labels
 }
 
-func addLabels(specName string, hierarchyLabels [][]string, nodeLabels []string) string {
-	labels := getLabels(hierarchyLabels, nodeLabels)
+// AddLabels 将标签以`[label01, label02]`的格式追加到用例名后
+func AddLabels(specName string, hierarchyLabels [][]string, nodeLabels []string) string {
+	labels := GetLabels(hierarchyLabels, nodeLabels)
 	if len(labels) != 0 {
 		specName += " " + fmt.Sprintf("[%s]", strings.Join(labels, ", "))
 	}
 	return specName
 }
 
+// GenCaseAttributes 生成用例的公共属性，静态加载与动态加载均通过该函数生成，保证两种模式下的属性键一致
+func GenCaseAttributes(containerName, leafName, specName string, labels []string) map[string]string {
+	var nameList string
+	if marshalNameList, err := json.Marshal([]string{containerName, leafName}); err == nil {
+		nameList = string(marshalNameList)
+	}
+	var labelList string
+	var owner string
+	var description string
+	if len(labels) > 0 {
+		if marshalLabelList, err := json.Marshal(labels); err == nil {
+			labelList = string(marshalLabelList)
+		}
+		for _, label := range labels {
+			if strings.HasPrefix(label, "owner:") {
+				owner = strings.TrimSpace(strings.TrimPrefix(label, "owner:"))
+			}
+			if strings.HasPrefix(label, "description:") {
+				description = strings.TrimSpace(strings.TrimPrefix(label, "description:"))
+			}
+		}
+	}
+	return map[string]string{
+		"nameList":               nameList,
+		"label":                  labelList,
+		"tags":                   labelList,
+		"owner":                  owner,
+		"description":            description,
+		"testsolar_requests_key": specName,
+	}
+}
+
+// LocationAttributeKeys 用例定义位置相关的属性键
+var LocationAttributeKeys = []string{"file", "line", "column", "containerLocations"}
+
+// GenLocationAttributes 生成用例定义位置相关的属性，containerLocations为从外到内各层容器节点的`file:line`
+func GenLocationAttributes(file string, line, column int, containerLocations []string) map[string]string {
+	attributes := map[string]string{
+		"file":   file,
+		"line":   strconv.Itoa(line),
+		"column": strconv.Itoa(column),
+	}
+	if len(containerLocations) > 0 {
+		if marshalLocations, err := json.Marshal(containerLocations); err == nil {
+			attributes["containerLocations"] = string(marshalLocations)
+		}
+	}
+	return attributes
+}
+
+// sourceColumns 读取源码计算指定行第一个非空白字符所在的列，同一文件只读取一次
+type sourceColumns struct {
+	lines map[string][]string
+}
+
+func newSourceColumns() *sourceColumns {
+	return &sourceColumns{lines: map[string][]string{}}
+}
+
+// find 返回文件指定行第一个非空白字符所在的列(从1开始)，文件不可读时返回0
+func (c *sourceColumns) find(fileName string, line int) int {
+	lines, ok := c.lines[fileName]
+	if !ok {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			log.Printf("read source file %s failed, err: %v", fileName, err)
+		} else {
+			lines = splitByNewline(string(content))
+		}
+		c.lines[fileName] = lines
+	}
+	if line <= 0 || line > len(lines) {
+		return 0
+	}
+	text := lines[line-1]
+	return len(text) - len(strings.TrimLeft(text, " \t")) + 1
+}
+
 func splitByNewline(s string) []string {
 	return strings.Split(s, "\n")
 }
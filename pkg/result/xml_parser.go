@@ -1,6 +1,7 @@
 package result
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
@@ -141,3 +142,39 @@ func ParseXmlResultFile(projPath string, path string) ([]*sdkModel.TestResult, e
 
 	return testResults, nil
 }
+
+// JUnitCase ginkgo v1 dry run生成的JUnit报告中的用例
+type JUnitCase struct {
+	Name string
+	// Pending 报告中标记为skipped的pending用例
+	Pending bool
+}
+
+// ParseJUnitCases 从ginkgo v1 dry run生成的JUnit报告中读取用例，用例名由各层容器以及用例节点的文本以空格拼接而成
+// 报告中标记为skipped的用例作为pending用例返回，与静态解析以及ginkgo v2的加载结果保持一致，BeforeSuite/AfterSuite节点会被忽略
+func ParseJUnitCases(path string) ([]*JUnitCase, error) {
+	buff, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := xmlquery.Parse(strings.NewReader(string(buff)))
+	if err != nil {
+		return nil, err
+	}
+	root := xmlquery.FindOne(doc, "//testsuite")
+	if root == nil {
+		return nil, fmt.Errorf("can't find testsuite in %s", path)
+	}
+	var cases []*JUnitCase
+	for _, testcase := range root.SelectElements("/testcase") {
+		name := testcase.SelectAttr("name")
+		if name == "" || name == "BeforeSuite" || name == "AfterSuite" {
+			continue
+		}
+		cases = append(cases, &JUnitCase{
+			Name:    name,
+			Pending: testcase.SelectElement("/skipped") != nil,
+		})
+	}
+	return cases, nil
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	cmdpkg "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/cmdline"
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+)
+
+// RunGoTest 执行包内的标准go测试，tcNames为顶层测试函数名或者`TestXxx/sub`形式的子测试名
+// goTests为顶层测试函数名到所在文件名的映射，用于生成测试结果的用例路径
+// 测试二进制的输出通过test2json转换为json事件后解析，没有go工具链时直接解析`-test.v`的输出，每个测试(包括子测试)上报一个测试结果
+func RunGoTest(projPath string, pkgBin string, packagePath string, goTests map[string]string, tcNames []string) ([]*sdkModel.TestResult, error) {
+	var testResults []*sdkModel.TestResult
+	caseName := func(testName string) string {
+		top := strings.SplitN(testName, "/", 2)[0]
+		if file, ok := goTests[top]; ok {
+			return filepath.Join(packagePath, file) + "?" + testName
+		}
+		return packagePath + "?" + testName
+	}
+	attributes := map[string]string{"framework": ginkgoTestcase.FrameworkGoTest}
+	// go test执行测试二进制时以包目录作为工作目录，保持一致以便测试读取相对路径下的文件
+	workDir := filepath.Join(projPath, packagePath)
+	var startTime time.Time
+	var errMessage string
+	for _, pattern := range cmdpkg.GenGoTestRunPatterns(tcNames) {
+		cmdline, parseOutput := goTestCmdline(packagePath, pkgBin, "-test.run "+cmdpkg.ShellQuote(pattern))
+		cmdline += goCoverDirArg(false)
+		log.Printf("Run cmdline %s", cmdline)
+		startTime = time.Now()
+		stdout, stderr, err := ginkgoUtil.RunCommandWithOutput(cmdline, workDir)
+		log.Printf("Run test command cost %.2fs", time.Since(startTime).Seconds())
+		if err != nil {
+			log.Printf("Command exit code: %v", err)
+		}
+		results := parseOutput(stdout, caseName, attributes)
+		if len(results) == 0 {
+			log.Printf("No test matches pattern %s, stdout: %s, stderr: %s", pattern, stdout, stderr)
+			errMessage = stderr
+			if errMessage == "" {
+				errMessage = fmt.Sprintf("no test matches pattern %s, stdout: %s", pattern, stdout)
+			}
+		}
+		testResults = append(testResults, results...)
+	}
+	if len(testResults) == 0 && len(tcNames) > 0 {
+		// 没有任何测试开始执行，通常是测试二进制无法启动，此时将所有选中的测试标记为失败
+		return genGoTestFailedResults(tcNames, caseName, attributes, startTime, errMessage), nil
+	}
+	return testResults, nil
+}
+
+// genGoTestFailedResults 为选中的用例生成失败结果，caseName根据测试名生成`path?name`形式的用例名
+func genGoTestFailedResults(tcNames []string, caseName func(testName string) string, attributes map[string]string, startTime time.Time, message string) []*sdkModel.TestResult {
+	var testResults []*sdkModel.TestResult
+	for _, tcName := range tcNames {
+		testResults = append(testResults, &sdkModel.TestResult{
+			Test: &sdkModel.TestCase{
+				Name:       caseName(tcName),
+				Attributes: attributes,
+			},
+			ResultType: sdkModel.ResultTypeFailed,
+			StartTime:  startTime,
+			EndTime:    time.Now(),
+			Message:    ginkgoUtil.ShortenString(message, 512),
+			Steps: []*sdkModel.TestCaseStep{
+				{
+					StartTime:  startTime,
+					EndTime:    time.Now(),
+					Title:      "Error",
+					ResultType: sdkModel.ResultTypeFailed,
+					Logs: []*sdkModel.TestCaseLog{
+						{
+							Time:    startTime,
+							Level:   sdkModel.LogLevelError,
+							Content: message,
+						},
+					},
+				},
+			},
+		})
+	}
+	return testResults
+}
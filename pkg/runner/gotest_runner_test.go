@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	builder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
+
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunGoTest(t *testing.T) {
+	absPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	pkgBin, err := builder.BuildTestPackage(absPath, "gotest", false)
+	assert.NoError(t, err)
+	defer os.Remove(pkgBin)
+	goTests := map[string]string{
+		"TestAdd":      "gotest_test.go",
+		"TestReadFile": "gotest_test.go",
+		"TestSkipped":  "gotest_test.go",
+	}
+	results, err := RunGoTest(absPath, pkgBin, "gotest", goTests, []string{"TestAdd/zero", "TestReadFile", "TestSkipped"})
+	assert.NoError(t, err)
+	resultTypes := map[string]sdkModel.ResultType{}
+	for _, result := range results {
+		resultTypes[result.Test.Name] = result.ResultType
+		assert.Equal(t, "gotest", result.Test.Attributes["framework"])
+	}
+	assert.Equal(t, map[string]sdkModel.ResultType{
+		"gotest/gotest_test.go?TestReadFile": sdkModel.ResultTypeSucceed,
+		"gotest/gotest_test.go?TestSkipped":  sdkModel.ResultTypeIgnored,
+		"gotest/gotest_test.go?TestAdd":      sdkModel.ResultTypeSucceed,
+		"gotest/gotest_test.go?TestAdd/zero": sdkModel.ResultTypeSucceed,
+	}, resultTypes)
+
+	// 执行环境中没有go工具链时直接解析测试二进制的-test.v输出
+	bash, err := exec.LookPath("bash")
+	assert.NoError(t, err)
+	binDir := t.TempDir()
+	assert.NoError(t, os.Symlink(bash, filepath.Join(binDir, "bash")))
+	t.Setenv("PATH", binDir)
+	results, err = RunGoTest(absPath, pkgBin, "gotest", goTests, []string{"TestAdd/zero", "TestReadFile", "TestSkipped"})
+	assert.NoError(t, err)
+	verboseResultTypes := map[string]sdkModel.ResultType{}
+	for _, result := range results {
+		verboseResultTypes[result.Test.Name] = result.ResultType
+	}
+	assert.Equal(t, resultTypes, verboseResultTypes)
+
+	// 测试二进制无法执行时，选中的测试均标记为失败
+	results, err = RunGoTest(absPath, filepath.Join(absPath, "not_exist.test"), "gotest", goTests, []string{"TestAdd"})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "gotest/gotest_test.go?TestAdd", results[0].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[0].ResultType)
+}
@@ -30,11 +30,11 @@ func TestGenarateCommandLine(t *testing.T) {
 	cmdline = genarateCommandLine(extraArgs, jsonFileName, projPath, pkgBin, tcNames, true)
 	assert.Equal(t, expected, cmdline, "should return the expected command line")
 	extraArgs = `--ginkgo.label-filter "( label01||label02)"`
-	expected = "suite.test --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report=\"output.json\" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus=\"case01$|case02$\" --ginkgo.label-filter \"( label01||label02)\""
+	expected = "suite.test --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report=\"/data/workspace/output.json\" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus=\"case01$|case02$\" --ginkgo.label-filter \"( label01||label02)\""
 	cmdline = genarateCommandLine(extraArgs, jsonFileName, projPath, pkgBin, tcNames, false)
 	assert.Equal(t, expected, cmdline, "should return the expected command line")
 	extraArgs = ""
-	expected = "suite.test --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report=\"output.json\" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus=\"case01$|case02$\""
+	expected = "suite.test --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report=\"/data/workspace/output.json\" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus=\"case01$|case02$\""
 	cmdline = genarateCommandLine(extraArgs, jsonFileName, projPath, pkgBin, tcNames, false)
 	assert.Equal(t, expected, cmdline, "should return the expected command line")
 }
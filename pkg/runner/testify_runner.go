@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	cmdpkg "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/cmdline"
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+)
+
+// testifySelection 单个testify测试套中选中的测试方法，methods为空表示执行整个测试套
+type testifySelection struct {
+	suite   *ginkgoUtil.TestifySuite
+	methods []string
+}
+
+// groupTestifyCases 按测试套分组用例名，`MySuite/TestCreate/sub`形式的用例名以测试方法为单位执行
+func groupTestifyCases(suites []*ginkgoUtil.TestifySuite, tcNames []string) []*testifySelection {
+	suiteByName := map[string]*ginkgoUtil.TestifySuite{}
+	for _, suite := range suites {
+		suiteByName[suite.Name] = suite
+	}
+	var selections []*testifySelection
+	selectionByName := map[string]*testifySelection{}
+	wholeSuite := map[string]bool{}
+	for _, tcName := range tcNames {
+		levels := strings.SplitN(tcName, "/", 3)
+		suite, ok := suiteByName[levels[0]]
+		if !ok {
+			log.Printf("Can't find testify suite of testcase %s", tcName)
+			continue
+		}
+		selection, ok := selectionByName[suite.Name]
+		if !ok {
+			selection = &testifySelection{suite: suite}
+			selectionByName[suite.Name] = selection
+			selections = append(selections, selection)
+		}
+		if len(levels) == 1 {
+			wholeSuite[suite.Name] = true
+			continue
+		}
+		if !ginkgoUtil.ElementIsInSlice(levels[1], selection.methods) {
+			selection.methods = append(selection.methods, levels[1])
+		}
+	}
+	for _, selection := range selections {
+		if wholeSuite[selection.suite.Name] {
+			selection.methods = nil
+		}
+	}
+	return selections
+}
+
+// genTestifyMethodPattern 生成-testify.m参数，testify使用该正则匹配测试方法名
+func genTestifyMethodPattern(methods []string) string {
+	var quoted []string
+	for _, method := range methods {
+		quoted = append(quoted, regexp.QuoteMeta(method))
+	}
+	return fmt.Sprintf("^(%s)$", strings.Join(quoted, "|"))
+}
+
+// RunTestifyTest 执行testify测试套中的测试方法，tcNames为`MySuite/TestCreate`形式的用例名，也可以为`MySuite`表示执行整个测试套
+// 通过`-test.run '^TestEntry$' -testify.m '^TestCreate$'`选择测试方法，SetupTest/TearDownTest的失败发生在测试方法内，归属于对应的方法
+// SetupSuite失败时测试方法不会执行，未执行的方法标记为失败；TearDownSuite等测试套级别的失败以测试套名称单独上报
+func RunTestifyTest(projPath string, pkgBin string, packagePath string, suites []*ginkgoUtil.TestifySuite, tcNames []string) ([]*sdkModel.TestResult, error) {
+	var testResults []*sdkModel.TestResult
+	attributes := map[string]string{"framework": ginkgoTestcase.FrameworkTestify}
+	workDir := filepath.Join(projPath, packagePath)
+	for _, selection := range groupTestifyCases(suites, tcNames) {
+		suite := selection.suite
+		caseName := func(testName string) string {
+			levels := strings.SplitN(testName, "/", 3)
+			if len(levels) == 1 {
+				return filepath.Join(packagePath, suite.EntryFile) + "?" + suite.Name
+			}
+			path := filepath.Join(packagePath, suite.EntryFile)
+			if method := suite.Method(levels[1]); method != nil {
+				path = filepath.Join(packagePath, method.File)
+			}
+			return path + "?" + suite.Name + "/" + strings.Join(levels[1:], "/")
+		}
+		cmdline, parseOutput := goTestCmdline(packagePath, pkgBin, "-test.run "+cmdpkg.ShellQuote("^"+regexp.QuoteMeta(suite.Entry)+"$"))
+		cmdline += goCoverDirArg(false)
+		expected := selection.methods
+		if len(expected) == 0 {
+			expected = suite.MethodNames()
+		} else {
+			cmdline += " -testify.m " + cmdpkg.ShellQuote(genTestifyMethodPattern(expected))
+		}
+		log.Printf("Run cmdline %s", cmdline)
+		startTime := time.Now()
+		stdout, stderr, err := ginkgoUtil.RunCommandWithOutput(cmdline, workDir)
+		log.Printf("Run test command cost %.2fs", time.Since(startTime).Seconds())
+		if err != nil {
+			log.Printf("Command exit code: %v", err)
+		}
+		suiteAttributes := map[string]string{"suite": suite.Name, "suiteEntry": suite.Entry}
+		for k, v := range attributes {
+			suiteAttributes[k] = v
+		}
+		results := parseOutput(stdout, caseName, suiteAttributes)
+		testResults = append(testResults, attributeTestifyResults(suite, expected, results, caseName, suiteAttributes, startTime, stderr)...)
+	}
+	return testResults, nil
+}
+
+// attributeTestifyResults 处理入口函数(即测试套本身)的结果
+// 入口函数失败且部分方法没有执行时，说明SetupSuite失败或者测试套被跳过，此时将未执行的方法标记为相同的结果
+// 所有方法均已执行但入口函数本身仍有失败输出时，说明TearDownSuite等测试套级别的逻辑失败，以测试套名称上报该失败
+func attributeTestifyResults(suite *ginkgoUtil.TestifySuite, expected []string, results []*sdkModel.TestResult, caseName func(testName string) string, attributes map[string]string, startTime time.Time, stderr string) []*sdkModel.TestResult {
+	var suiteResult *sdkModel.TestResult
+	var methodResults []*sdkModel.TestResult
+	ran := map[string]bool{}
+	for _, result := range results {
+		if result.Test.Name == caseName(suite.Entry) {
+			suiteResult = result
+			continue
+		}
+		methodResults = append(methodResults, result)
+		name := strings.SplitN(result.Test.Name, "?", 2)[1]
+		ran[strings.SplitN(name, "/", 3)[1]] = true
+	}
+	var missing []string
+	for _, method := range expected {
+		if !ran[method] {
+			missing = append(missing, method)
+		}
+	}
+	if suiteResult == nil {
+		// 测试二进制无法启动，入口函数没有执行
+		if len(missing) == 0 {
+			return methodResults
+		}
+		var names []string
+		for _, method := range missing {
+			names = append(names, suite.Entry+"/"+method)
+		}
+		message := stderr
+		if message == "" {
+			message = fmt.Sprintf("testify suite %s was not run by %s", suite.Name, suite.Entry)
+		}
+		return append(methodResults, genGoTestFailedResults(names, caseName, attributes, startTime, message)...)
+	}
+	switch {
+	case suiteResult.ResultType == sdkModel.ResultTypeSucceed:
+		return methodResults
+	case len(missing) > 0:
+		for _, method := range missing {
+			methodResults = append(methodResults, &sdkModel.TestResult{
+				Test: &sdkModel.TestCase{
+					Name:       caseName(suite.Entry + "/" + method),
+					Attributes: attributes,
+				},
+				ResultType: suiteResult.ResultType,
+				StartTime:  suiteResult.StartTime,
+				EndTime:    suiteResult.EndTime,
+				Message:    suiteResult.Message,
+				Steps:      suiteResult.Steps,
+			})
+		}
+		return methodResults
+	case suiteResult.ResultType == sdkModel.ResultTypeFailed && len(suiteResult.Steps) > 0 && len(suiteResult.Steps[0].Logs) > 0:
+		return append(methodResults, suiteResult)
+	default:
+		return methodResults
+	}
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	builder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
+	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
+
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTestifyTest(t *testing.T) {
+	absPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	pkgBin, err := builder.BuildTestPackage(absPath, "testify", false)
+	assert.NoError(t, err)
+	defer os.Remove(pkgBin)
+	suites, err := ginkgoUtil.FindTestifySuites(filepath.Join(absPath, "testify"))
+	assert.NoError(t, err)
+
+	results, err := RunTestifyTest(absPath, pkgBin, "testify", suites, []string{"UserSuite/TestCreate", "UserSuite/TestFail"})
+	assert.NoError(t, err)
+	resultTypes := map[string]sdkModel.ResultType{}
+	for _, result := range results {
+		resultTypes[result.Test.Name] = result.ResultType
+		assert.Equal(t, "testify", result.Test.Attributes["framework"])
+		assert.Equal(t, "UserSuite", result.Test.Attributes["suite"])
+	}
+	// 入口函数因TestFail失败，但所有选中的方法均已执行，不单独上报测试套结果
+	assert.Equal(t, map[string]sdkModel.ResultType{
+		"testify/user_test.go?UserSuite/TestCreate": sdkModel.ResultTypeSucceed,
+		"testify/user_test.go?UserSuite/TestFail":   sdkModel.ResultTypeFailed,
+	}, resultTypes)
+
+	// SetupSuite失败时测试方法没有执行，使用测试套的失败信息
+	results, err = RunTestifyTest(absPath, pkgBin, "testify", suites, []string{"BrokenSetupSuite"})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "testify/broken_test.go?BrokenSetupSuite/TestNeverRuns", results[0].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[0].ResultType)
+	assert.Contains(t, results[0].Message, "setup suite failed")
+
+	// TearDownSuite失败时以测试套名称上报
+	results, err = RunTestifyTest(absPath, pkgBin, "testify", suites, []string{"BrokenTeardownSuite/TestPasses"})
+	assert.NoError(t, err)
+	resultTypes = map[string]sdkModel.ResultType{}
+	for _, result := range results {
+		resultTypes[result.Test.Name] = result.ResultType
+	}
+	assert.Equal(t, map[string]sdkModel.ResultType{
+		"testify/broken_test.go?BrokenTeardownSuite/TestPasses": sdkModel.ResultTypeSucceed,
+		"testify/suite_test.go?BrokenTeardownSuite":             sdkModel.ResultTypeFailed,
+	}, resultTypes)
+
+	// 测试二进制无法执行时，选中的方法均标记为失败
+	results, err = RunTestifyTest(absPath, filepath.Join(absPath, "not_exist.test"), "testify", suites, []string{"UserSuite/TestCreate"})
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "testify/user_test.go?UserSuite/TestCreate", results[0].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeFailed, results[0].ResultType)
+}
@@ -1,10 +1,16 @@
 package runner
 
 import (
+	"fmt"
 	"log"
+	"os"
 	"os/exec"
 
+	cmdpkg "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/cmdline"
+	ginkgoResult "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/result"
 	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
+
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
 )
 
 func GetGinkgoVersion(testcases []*ginkgoTestcase.TestCase) string {
@@ -23,3 +29,30 @@ func CheckGinkgoCli() bool {
 	}
 	return true
 }
+
+// goCoverDirArg 返回输出覆盖率数据需要追加到执行命令中的参数，环境变量GOCOVERDIR未设置时返回空字符串
+// 直接执行测试二进制时不会读取GOCOVERDIR，需要通过-test.gocoverdir参数指定；通过ginkgo命令执行时该参数需要放在`--`之后传递给测试二进制
+func goCoverDirArg(viaGinkgoCli bool) string {
+	coverDir := os.Getenv("GOCOVERDIR")
+	if coverDir == "" {
+		return ""
+	}
+	arg := " -test.gocoverdir=" + cmdpkg.ShellQuote(coverDir)
+	if viaGinkgoCli {
+		return " --" + arg
+	}
+	return arg
+}
+
+// goTestOutputParser 解析标准go测试输出的函数
+type goTestOutputParser func(output string, caseName func(testName string) string, attributes map[string]string) []*sdkModel.TestResult
+
+// goTestCmdline 返回以`-test.v`执行测试二进制的命令以及输出的解析函数，args为追加到测试二进制的参数
+// 执行环境中有go工具链时通过`go tool test2json`将输出转换为json事件，否则直接执行测试二进制并解析`-test.v`的文本输出
+func goTestCmdline(packagePath string, pkgBin string, args string) (string, goTestOutputParser) {
+	if _, err := exec.LookPath("go"); err != nil {
+		log.Println("go command not found, parse the verbose output of test binary")
+		return fmt.Sprintf("%s -test.v %s", pkgBin, args), ginkgoResult.ParseGoTestVerboseOutput
+	}
+	return fmt.Sprintf("go tool test2json -p %s %s -test.v %s", cmdpkg.ShellQuote(packagePath), pkgBin, args), ginkgoResult.ParseGoTestEvents
+}
@@ -35,3 +35,11 @@ func TestCheckGinkgoCli(t *testing.T) {
 		assert.True(t, CheckGinkgoCli())
 	}
 }
+
+func TestGoCoverDirArg(t *testing.T) {
+	t.Setenv("GOCOVERDIR", "")
+	assert.Equal(t, "", goCoverDirArg(false))
+	t.Setenv("GOCOVERDIR", "/tmp/cover dir")
+	assert.Equal(t, " -test.gocoverdir='/tmp/cover dir'", goCoverDirArg(false))
+	assert.Equal(t, " -- -test.gocoverdir='/tmp/cover dir'", goCoverDirArg(true), "通过ginkgo命令执行时参数需要传递给测试二进制")
+}
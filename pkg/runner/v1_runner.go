@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"path"
-	"strings"
 	"time"
 
 	cmdpkg "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/cmdline"
@@ -14,14 +13,22 @@ import (
 	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
 )
 
-func RunGinkgoV1Test(projPath string, pkgBin string, filepath string, tcNames []string) ([]*sdkModel.TestResult, error) {
+// RunGinkgoV1Test 执行ginkgo v1用例，packagePath为用例所在包相对于projPath的路径
+// 二进制文件可能位于编译输出目录下，因此在包目录下执行而不是根据二进制文件的位置推断
+func RunGinkgoV1Test(projPath string, pkgBin string, packagePath string, filepath string, tcNames []string) ([]*sdkModel.TestResult, error) {
 	var testResults []*sdkModel.TestResult
 	_, filename := path.Split(pkgBin)
-	outputXmlFile := path.Join(projPath, fmt.Sprintf("%s_output.xml", filename))
-	cmdline := pkgBin + fmt.Sprintf(` --ginkgo.v --ginkgo.noColor --ginkgo.trace --ginkgo.reportFile="%s" --ginkgo.focus="%s" `, outputXmlFile, cmdpkg.GenTestCaseFocusName(tcNames))
+	// 每次执行的报告写入独立的目录，避免并发执行时相互覆盖
+	reportDir, cleanup, err := ginkgoUtil.NewReportDir(projPath, "run-"+filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	outputXmlFile := path.Join(reportDir, fmt.Sprintf("%s_output.xml", filename))
+	cmdline := pkgBin + fmt.Sprintf(` --ginkgo.v --ginkgo.noColor --ginkgo.trace --ginkgo.reportFile="%s" --ginkgo.focus="%s" `, outputXmlFile, cmdpkg.GenTestCaseFocusName(tcNames)) + goCoverDirArg(false)
 	log.Printf("Run cmdline %s", cmdline)
 	startTime := time.Now()
-	workDir := strings.TrimSuffix(pkgBin, ".test")
+	workDir := path.Join(projPath, packagePath)
 	_, stderr, err := ginkgoUtil.RunCommandWithOutput(cmdline, workDir)
 	delta := time.Since(startTime)
 	log.Printf("Run test command cost %.2fs", delta.Seconds())
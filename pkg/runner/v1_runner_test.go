@@ -7,6 +7,8 @@ import (
 
 	builder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
 
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,7 +21,24 @@ func TestRunGinkgoV1Test(t *testing.T) {
 	_, err = os.Stat(pkgBin)
 	assert.NoError(t, err)
 	defer os.Remove("../../testdata/demo.test")
-	testResult, err := RunGinkgoV1Test(absPath, "demo.test", "../../testdata/demo_test.go", []string{"Testcase"})
+	defer os.Remove("../../testdata/gotest.test")
+	defer os.Remove("../../testdata/testify.test")
+	testResult, err := RunGinkgoV1Test(absPath, "demo.test", "demo", "../../testdata/demo_test.go", []string{"Testcase"})
 	assert.NoError(t, err)
 	assert.NotEqual(t, len(testResult), 0)
 }
+
+func TestRunGinkgoV1TestWithBinaryDir(t *testing.T) {
+	absPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	// 二进制文件位于用例库之外的编译输出目录下，用例仍然需要在包目录下执行
+	t.Setenv("TESTSOLAR_TTP_BINARYDIR", t.TempDir())
+	defer os.RemoveAll(filepath.Join(absPath, ".testtool"))
+	pkgBin, err := builder.BuildTestPackage(absPath, "demo/v1", false)
+	assert.NoError(t, err)
+	testResults, err := RunGinkgoV1Test(absPath, pkgBin, "demo/v1", "demo/v1/v1_test.go", []string{"Testcase v1 context it"})
+	assert.NoError(t, err)
+	assert.Len(t, testResults, 1)
+	assert.Equal(t, "demo/v1/v1_test.go?Testcase v1 context it", testResults[0].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeSucceed, testResults[0].ResultType)
+}
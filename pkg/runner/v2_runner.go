@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path"
 	"strings"
 	"time"
 
@@ -29,9 +30,10 @@ func parseExtraArgs(extraArgs string) (*cmdpkg.CommandArgs, error) {
 	return cmdpkg.NewCmdArgsParseByCmdLine(extraArgs)
 }
 
-func genarateCommandLine(extraArgs, jsonFileName, projPath, pkgBin string, tcNames []string, hasClient bool) string {
+// genarateCommandLine 生成执行命令，json报告会写入outputDir目录下的jsonFileName文件
+func genarateCommandLine(extraArgs, jsonFileName, outputDir, pkgBin string, tcNames []string, hasClient bool) string {
 	if hasClient {
-		defaultCmdLine := fmt.Sprintf("ginkgo --v --no-color --trace --json-report %s --output-dir %s --always-emit-ginkgo-writer", jsonFileName, projPath)
+		defaultCmdLine := fmt.Sprintf("ginkgo --v --no-color --trace --json-report %s --output-dir %s --always-emit-ginkgo-writer", jsonFileName, outputDir)
 		cmdArgs, err := cmdpkg.NewCmdArgsParseByCmdLine(defaultCmdLine)
 		if err != nil {
 			log.Printf("Parse cmdline [%s] error: %v", defaultCmdLine, err)
@@ -54,10 +56,11 @@ func genarateCommandLine(extraArgs, jsonFileName, projPath, pkgBin string, tcNam
 		cmdline := cmdArgs.GenerateCmdLineStr()
 		return cmdline
 	} else {
+		jsonFilePath := path.Join(outputDir, jsonFileName)
 		if extraArgs == "" {
-			return pkgBin + fmt.Sprintf(` --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report="%s" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus="%s"`, jsonFileName, cmdpkg.GenTestCaseFocusName(tcNames))
+			return pkgBin + fmt.Sprintf(` --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report="%s" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus="%s"`, jsonFilePath, cmdpkg.GenTestCaseFocusName(tcNames))
 		} else {
-			return pkgBin + fmt.Sprintf(` --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report="%s" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus="%s" %s`, jsonFileName, cmdpkg.GenTestCaseFocusName(tcNames), extraArgs)
+			return pkgBin + fmt.Sprintf(` --ginkgo.v --ginkgo.no-color --ginkgo.trace --ginkgo.json-report="%s" --ginkgo.always-emit-ginkgo-writer --ginkgo.focus="%s" %s`, jsonFilePath, cmdpkg.GenTestCaseFocusName(tcNames), extraArgs)
 		}
 	}
 }
@@ -198,26 +201,32 @@ func getExpectedCases(cmdline, projPath, filepath, packPath string, tcNames []st
 	return finalCases
 }
 
-func RunGinkgoV2Test(projPath, pkgBin, filepath string, tcNames []string) ([]*sdkModel.TestResult, error) {
-	outputJsonFile := fmt.Sprintf("output-%s.json", ginkgoUtil.GenRandomString(8))
-	err := ginkgoUtil.RemoveFile(outputJsonFile)
+// RunGinkgoV2Test 执行ginkgo v2用例，packagePath为用例所在包相对于projPath的路径
+// 二进制文件可能位于编译输出目录下，因此结果中的包路径取自调用方而不是根据二进制文件的位置推断
+func RunGinkgoV2Test(projPath, pkgBin, packagePath, filepath string, tcNames []string) ([]*sdkModel.TestResult, error) {
+	// 每次执行的报告写入独立的目录，避免并发执行时相互覆盖
+	reportDir, cleanup, err := ginkgoUtil.NewReportDir(projPath, "run-"+filepath)
 	if err != nil {
-		log.Printf("failed to remove output json file, err: %s", err.Error())
+		return nil, err
 	}
-	cmdline := genarateCommandLine(os.Getenv("TESTSOLAR_TTP_EXTRAARGS"), outputJsonFile, projPath, pkgBin, tcNames, CheckGinkgoCli())
-	log.Printf("Run cmdline %s", cmdline)
-	stdout, stderr, err := ginkgoUtil.RunCommandWithOutput(cmdline, projPath)
+	defer cleanup()
+	outputJsonFile := path.Join(reportDir, "output.json")
+	hasClient := CheckGinkgoCli()
+	cmdline := genarateCommandLine(os.Getenv("TESTSOLAR_TTP_EXTRAARGS"), "output.json", reportDir, pkgBin, tcNames, hasClient)
+	// 覆盖率参数不参与dry run命令的生成
+	runCmdline := cmdline + goCoverDirArg(hasClient)
+	log.Printf("Run cmdline %s", runCmdline)
+	stdout, stderr, err := ginkgoUtil.RunCommandWithOutput(runCmdline, projPath)
 	if err != nil {
 		log.Printf("Command excute failed, stdout: %s, stderr %s, err: %v", stdout, stderr, err)
 	}
-	packPath := cmdpkg.ExtractPackPathFromBinFile(pkgBin, projPath)
 	if empty, err := ginkgoUtil.IsJsonFileEmpty(outputJsonFile); err != nil || empty {
 		// 如果输出结果文件为空，说明测试套执行失败，需要将本次期望执行的用例置为失败并上报
-		expectedCases := getExpectedCases(cmdline, projPath, filepath, packPath, tcNames)
+		expectedCases := getExpectedCases(cmdline, projPath, filepath, packagePath, tcNames)
 		return generateFailedCasesWhenSuitePanic(stdout, stderr, nil, expectedCases), nil
 	}
 	log.Printf("Parse json file %s", outputJsonFile)
-	resultParser, err := ginkgoResult.NewResultParser(outputJsonFile, projPath, packPath, filepath, true)
+	resultParser, err := ginkgoResult.NewResultParser(outputJsonFile, projPath, packagePath, filepath, true)
 	if err != nil {
 		log.Printf("instantiate result parser failed, err: %s", err.Error())
 		return nil, err
@@ -229,7 +238,7 @@ func RunGinkgoV2Test(projPath, pkgBin, filepath string, tcNames []string) ([]*sd
 	}
 	if suite != nil {
 		// 如果存在状态为panic的测试套，说明测试套执行失败，需要将本次期望执行的用例置为失败并上报
-		expectedCases := getExpectedCases(cmdline, projPath, filepath, packPath, tcNames)
+		expectedCases := getExpectedCases(cmdline, projPath, filepath, packagePath, tcNames)
 		return generateFailedCasesWhenSuitePanic("", "", suite, expectedCases), nil
 	}
 	return resultParser.Parse()
@@ -7,6 +7,8 @@ import (
 
 	builder "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/builder"
 
+	sdkModel "github.com/OpenTestSolar/testtool-sdk-golang/model"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,8 +21,49 @@ func TestRunGinkgoV2Test(t *testing.T) {
 	_, err = os.Stat(pkgBin)
 	assert.NoError(t, err)
 	defer os.Remove("../../testdata/demo.test")
-	testResult, err := RunGinkgoV2Test(absPath, "demo.test", "../../testdata/demo_test.go", []string{"Testcase"})
+	defer os.Remove("../../testdata/gotest.test")
+	defer os.Remove("../../testdata/testify.test")
+	testResult, err := RunGinkgoV2Test(absPath, "demo.test", "demo", "../../testdata/demo_test.go", []string{"Testcase cont demo test"})
 	assert.NoError(t, err)
-	defer os.Remove("../../testdata/output.json")
 	assert.NotEqual(t, len(testResult), 0)
+	// 报告写入独立的临时目录，执行结束后不会遗留在当前目录或者用例库中
+	outputFiles, err := filepath.Glob("output*.json")
+	assert.NoError(t, err)
+	assert.Len(t, outputFiles, 0)
+	outputFiles, err = filepath.Glob(filepath.Join(absPath, "output*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, outputFiles, 0)
+	// 指定调试产物目录时保留报告文件
+	artifactDir := t.TempDir()
+	t.Setenv("TESTSOLAR_TTP_ARTIFACTDIR", artifactDir)
+	_, err = RunGinkgoV2Test(absPath, "demo.test", "demo", "../../testdata/demo_test.go", []string{"Testcase cont demo test"})
+	assert.NoError(t, err)
+	outputFiles, err = filepath.Glob(filepath.Join(artifactDir, "*", "output.json"))
+	assert.NoError(t, err)
+	assert.Len(t, outputFiles, 1)
+}
+
+func TestRunGinkgoV2TestWithBinaryDir(t *testing.T) {
+	absPath, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	// 二进制文件位于用例库之外的编译输出目录下，结果中的用例路径仍然相对于包目录
+	t.Setenv("TESTSOLAR_TTP_BINARYDIR", t.TempDir())
+	defer os.RemoveAll(filepath.Join(absPath, ".testtool"))
+	pkgBin, err := builder.BuildTestPackage(absPath, "demo/book", false)
+	assert.NoError(t, err)
+	testResults, err := RunGinkgoV2Test(absPath, pkgBin, "demo/book", "demo/book/book_test.go", []string{"Testcase Book Read Book Read two books"})
+	assert.NoError(t, err)
+	var names []string
+	for _, result := range testResults {
+		names = append(names, result.Test.Name)
+	}
+	assert.Contains(t, names, "demo/book/book_test.go?Testcase Book Read Book Read two books")
+	// 测试套崩溃时期望执行的用例以包路径上报为失败，而不是二进制文件所在的路径
+	crashBin := filepath.Join(t.TempDir(), "book.test")
+	assert.NoError(t, os.WriteFile(crashBin, []byte("#!/bin/sh\necho crashed >&2\nexit 1\n"), 0755))
+	testResults, err = RunGinkgoV2Test(absPath, crashBin, "demo/book", "demo/book", []string{"Testcase Book Read Book Read two books"})
+	assert.NoError(t, err)
+	assert.Len(t, testResults, 1)
+	assert.Equal(t, "demo/book?Testcase Book Read Book Read two books", testResults[0].Test.Name)
+	assert.Equal(t, sdkModel.ResultTypeFailed, testResults[0].ResultType)
 }
@@ -1,17 +1,27 @@
 package selector
 
 import (
+	"fmt"
+	"log"
 	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
 )
 
 type TestSelector struct {
 	Value      string
 	Path       string
+	Line       int // 形如`path/foo_test.go:42`的选择器中指定的行号，未指定时为0
 	Name       string
 	Attributes map[string]string
 }
 
+var locationRegex = regexp.MustCompile(`^(.+\.go):(\d+)$`)
+
 func NewTestSelector(selector string) (*TestSelector, error) {
 	u, err := url.Parse(selector)
 	if err != nil {
@@ -41,9 +51,15 @@ func NewTestSelector(selector string) (*TestSelector, error) {
 			}
 		}
 	}
+	line := 0
+	if match := locationRegex.FindStringSubmatch(path); match != nil {
+		path = match[1]
+		line, _ = strconv.Atoi(match[2])
+	}
 	testSelector := &TestSelector{
 		Value:      selector,
 		Path:       path,
+		Line:       line,
 		Name:       name,
 		Attributes: attributes,
 	}
@@ -55,8 +71,110 @@ func (ts *TestSelector) IsExclude() bool {
 	return ok && exclude == "true"
 }
 
+// MatchPath 判断路径是否位于选择器路径下，选择器路径为目录时匹配目录下所有路径
+func (ts *TestSelector) MatchPath(path string) bool {
+	selectorPath := filepath.Clean(ts.Path)
+	path = filepath.Clean(path)
+	if selectorPath == "." || selectorPath == path {
+		return true
+	}
+	return strings.HasPrefix(path, selectorPath+string(filepath.Separator))
+}
+
+// OverlapsPath 判断路径与选择器路径是否存在包含关系，即路径位于选择器路径下或者选择器路径位于该路径下
+func (ts *TestSelector) OverlapsPath(path string) bool {
+	if ts.MatchPath(path) {
+		return true
+	}
+	path = filepath.Clean(path)
+	selectorPath := filepath.Clean(ts.Path)
+	return path == "." || strings.HasPrefix(selectorPath, path+string(filepath.Separator))
+}
+
+// FiltersTestCases 判断选择器是否指定了用例名、行号或者属性，此时只能匹配具体的用例，无法匹配包或者文件级别的用例
+func (ts *TestSelector) FiltersTestCases() bool {
+	return ts.Name != "" || ts.Line > 0 || len(ts.filterAttributes()) > 0
+}
+
+// Match 判断用例是否满足选择器的路径、行号、名称以及属性条件，加载与执行时均通过该函数过滤用例
+func (ts *TestSelector) Match(tc *ginkgoTestcase.TestCase) bool {
+	if ts.Line > 0 {
+		if !tc.MatchLocation(ts.Path, ts.Line) {
+			return false
+		}
+	} else if !ts.MatchPath(tc.Path) {
+		return false
+	}
+	if !ts.MatchName(tc.Name) {
+		return false
+	}
+	return tc.MatchAttr(ts.filterAttributes())
+}
+
+// MatchName 判断用例名称是否与选择器名称一致，或者用例位于以选择器名称命名的容器下
+// 例如选择器名称`Demo context`可以匹配用例`Demo context case1`，但不能匹配`Demo contexts case1`
+// 标准go测试的子测试以`/`分隔，选择器名称`TestAdd`可以匹配子测试`TestAdd/zero`
+func (ts *TestSelector) MatchName(name string) bool {
+	if ts.Name == "" || name == ts.Name {
+		return true
+	}
+	return strings.HasPrefix(name, ts.Name+" ") || strings.HasPrefix(name, ts.Name+"/")
+}
+
+// filterAttributes 返回用于匹配用例属性的选择器属性，排除exclude等控制字段
+func (ts *TestSelector) filterAttributes() map[string]string {
+	attributes := map[string]string{}
+	for k, v := range ts.Attributes {
+		if k != "exclude" {
+			attributes[k] = v
+		}
+	}
+	return attributes
+}
+
+// ParseExcludeSelectors 解析选择器列表中声明了exclude=true的选择器
+func ParseExcludeSelectors(testSelectors []string) []*TestSelector {
+	var excludeSelectors []*TestSelector
+	for _, selector := range testSelectors {
+		testSelector, err := NewTestSelector(selector)
+		if err != nil {
+			log.Printf("Ignore invalid test selector: %s", selector)
+			continue
+		}
+		if testSelector.IsExclude() {
+			excludeSelectors = append(excludeSelectors, testSelector)
+		}
+	}
+	return excludeSelectors
+}
+
+// ExcludeTestCases 过滤掉满足任意一个排除选择器的用例
+func ExcludeTestCases(testcases []*ginkgoTestcase.TestCase, excludeSelectors []*TestSelector) []*ginkgoTestcase.TestCase {
+	if len(excludeSelectors) == 0 {
+		return testcases
+	}
+	var filtered []*ginkgoTestcase.TestCase
+	for _, testcase := range testcases {
+		excluded := false
+		for _, excludeSelector := range excludeSelectors {
+			if excludeSelector.Match(testcase) {
+				log.Printf("Exclude testcase %s by selector %s", testcase.GetSelector(), excludeSelector.Value)
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			filtered = append(filtered, testcase)
+		}
+	}
+	return filtered
+}
+
 func (ts *TestSelector) String() string {
 	strSelector := ts.Path
+	if ts.Line > 0 {
+		strSelector += fmt.Sprintf(":%d", ts.Line)
+	}
 	if ts.Name != "" {
 		strSelector += "?" + ts.Name
 	}
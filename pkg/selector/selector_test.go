@@ -3,6 +3,7 @@ package selector
 import (
 	"testing"
 
+	ginkgoTestcase "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/testcase"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,3 +42,76 @@ func TestNewTestSelector(t *testing.T) {
 	assert.Equal(t, "test name", ts4.Name)
 	assert.Equal(t, "value=1", ts4.Attributes["attr1"])
 }
+
+func TestNewTestSelectorWithLine(t *testing.T) {
+	ts, err := NewTestSelector("path/foo_test.go:42")
+	assert.NoError(t, err)
+	assert.Equal(t, "path/foo_test.go", ts.Path)
+	assert.Equal(t, 42, ts.Line)
+	assert.Equal(t, "path/foo_test.go:42", ts.String())
+
+	ts, err = NewTestSelector("path/foo_test.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "path/foo_test.go", ts.Path)
+	assert.Equal(t, 0, ts.Line)
+}
+
+func TestExcludeTestCases(t *testing.T) {
+	testcases := []*ginkgoTestcase.TestCase{
+		{Path: "demo/demo_test.go", Name: "Demo case01", Attributes: map[string]string{"label": `["smoke"]`}},
+		{Path: "demo/demo_test.go", Name: "Demo case02", Attributes: map[string]string{"label": `["slow"]`}},
+		{Path: "demo/v1/v1_test.go", Name: "V1 case", Attributes: map[string]string{}},
+		{Path: "demo_other/other_test.go", Name: "Other case", Attributes: map[string]string{}},
+	}
+	names := func(testcases []*ginkgoTestcase.TestCase) []string {
+		var names []string
+		for _, testcase := range testcases {
+			names = append(names, testcase.Name)
+		}
+		return names
+	}
+	excludes := ParseExcludeSelectors([]string{"demo", "demo/v1?exclude=true"})
+	assert.Len(t, excludes, 1)
+	assert.Equal(t, []string{"Demo case01", "Demo case02", "Other case"}, names(ExcludeTestCases(testcases, excludes)))
+
+	excludes = ParseExcludeSelectors([]string{"demo/demo_test.go?name=Demo%20case01&exclude=true"})
+	assert.Equal(t, []string{"Demo case02", "V1 case", "Other case"}, names(ExcludeTestCases(testcases, excludes)))
+
+	excludes = ParseExcludeSelectors([]string{".?label=slow&exclude=true"})
+	assert.Equal(t, []string{"Demo case01", "V1 case", "Other case"}, names(ExcludeTestCases(testcases, excludes)))
+
+	assert.Len(t, ExcludeTestCases(testcases, nil), 4)
+}
+
+func TestMatchName(t *testing.T) {
+	ts, err := NewTestSelector("path/to/test_test.go?Demo context")
+	assert.NoError(t, err)
+	assert.True(t, ts.MatchName("Demo context"))
+	assert.True(t, ts.MatchName("Demo context case01"))
+	assert.False(t, ts.MatchName("Demo contexts case01"))
+	ts, err = NewTestSelector("path/to/test_test.go")
+	assert.NoError(t, err)
+	assert.True(t, ts.MatchName("Demo contexts case01"))
+	ts, err = NewTestSelector("path/to/plain_test.go?TestAdd")
+	assert.NoError(t, err)
+	assert.True(t, ts.MatchName("TestAdd/zero"))
+	assert.False(t, ts.MatchName("TestAddAll"))
+}
+
+func TestOverlapsPathAndFiltersTestCases(t *testing.T) {
+	ts, err := NewTestSelector("demo/demo_test.go?name=Demo&exclude=true")
+	assert.NoError(t, err)
+	assert.True(t, ts.OverlapsPath("demo"))
+	assert.True(t, ts.OverlapsPath("demo/demo_test.go"))
+	assert.True(t, ts.OverlapsPath("."))
+	assert.False(t, ts.OverlapsPath("demo/v1"))
+	assert.False(t, ts.OverlapsPath("dem"))
+	assert.True(t, ts.FiltersTestCases())
+	ts, err = NewTestSelector("demo/v1?exclude=true")
+	assert.NoError(t, err)
+	assert.True(t, ts.OverlapsPath("demo/v1/v1_test.go"))
+	assert.False(t, ts.FiltersTestCases())
+	ts, err = NewTestSelector("demo?label=slow&exclude=true")
+	assert.NoError(t, err)
+	assert.True(t, ts.FiltersTestCases())
+}
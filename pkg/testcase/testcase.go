@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	ginkgoUtil "github.com/OpenTestSolar/testtool-golang-ginkgo/pkg/util"
@@ -18,6 +20,14 @@ type TestCase struct {
 	Attributes map[string]string
 }
 
+// 非ginkgo用例framework属性的取值
+const (
+	// FrameworkGoTest 标准go测试(testing.T)
+	FrameworkGoTest = "gotest"
+	// FrameworkTestify testify测试套(suite.Run)中的测试方法
+	FrameworkTestify = "testify"
+)
+
 func (tc *TestCase) GetSelector() string {
 	strSelector := tc.Path
 	if tc.Name != "" {
@@ -76,6 +86,19 @@ func (tc *TestCase) MatchAttr(attr map[string]string) bool {
 	return false
 }
 
+// MatchLocation 判断用例的叶子节点或者任意一层容器节点是否定义在文件path的第line行
+func (tc *TestCase) MatchLocation(path string, line int) bool {
+	path = filepath.Clean(path)
+	if filepath.Clean(tc.Attributes["file"]) == path && tc.Attributes["line"] == strconv.Itoa(line) {
+		return true
+	}
+	containerLocations, err := parseAttrSliceValue(tc.Attributes["containerLocations"])
+	if err != nil {
+		return false
+	}
+	return ginkgoUtil.ElementIsInSlice(fmt.Sprintf("%s:%d", path, line), containerLocations)
+}
+
 func ParseTestCaseBySelector(selector string) (*TestCase, error) {
 	selector = strings.Replace(selector, "+", "%2B", -1)
 	u, err := url.Parse(selector)
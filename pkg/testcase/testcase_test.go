@@ -109,3 +109,22 @@ func TestGetSelectorEmptyName(t *testing.T) {
 	expected := "/path/to/test"
 	assert.Equal(t, selector, expected)
 }
+
+func TestMatchLocation(t *testing.T) {
+	tc := &TestCase{
+		Path: "demo/demo_test.go",
+		Name: "demo case",
+		Attributes: map[string]string{
+			"file":               "demo/demo_test.go",
+			"line":               "42",
+			"column":             "3",
+			"containerLocations": `["demo/demo_test.go:10","demo/demo_test.go:20"]`,
+		},
+	}
+	assert.True(t, tc.MatchLocation("demo/demo_test.go", 42))
+	assert.True(t, tc.MatchLocation("./demo/demo_test.go", 10))
+	assert.True(t, tc.MatchLocation("demo/demo_test.go", 20))
+	assert.False(t, tc.MatchLocation("demo/demo_test.go", 30))
+	assert.False(t, tc.MatchLocation("demo/other_test.go", 42))
+	assert.False(t, (&TestCase{Attributes: map[string]string{}}).MatchLocation("demo/demo_test.go", 42))
+}
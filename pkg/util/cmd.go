@@ -2,11 +2,13 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"syscall"
 
 	"github.com/sourcegraph/conc"
 )
@@ -85,12 +87,53 @@ func RunCommand(cmdline string, projPath string, isWait bool, redirect bool) (io
 }
 
 func RunCommandWithOutput(cmdline string, projPath string) (string, string, error) {
+	return RunCommandWithEnvsAndOutput(cmdline, projPath, nil)
+}
+
+// RunCommandWithEnvsAndOutput 在指定目录下执行命令，envs为在当前环境变量基础上额外设置的环境变量
+func RunCommandWithEnvsAndOutput(cmdline string, projPath string, envs map[string]string) (string, string, error) {
+	return RunCommandWithContextAndOutput(context.Background(), cmdline, projPath, envs)
+}
+
+// RunCommandWithContextAndOutput 在指定目录下执行命令，ctx可以被取消时命令在独立的进程组中执行
+// ctx被取消或者超时后终止整个进程组，避免bash启动的子进程(如go test -c调用的编译器)在返回后继续运行，此时返回ctx的错误
+func RunCommandWithContextAndOutput(ctx context.Context, cmdline string, projPath string, envs map[string]string) (string, string, error) {
 	var stdout, stderr string
 	var wg conc.WaitGroup
-	outStream, errStream, err := RunCommand(cmdline, projPath, false, true)
+	cmd := exec.CommandContext(ctx, "bash", "-c", cmdline)
+	cmd.Dir = projPath
+	cmd.Env = os.Environ()
+	for k, v := range envs {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	if ctx.Done() != nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	outStream, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", "", err
 	}
+	errStream, err := cmd.StderrPipe()
+	if err != nil {
+		return "", "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", "", err
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				// 子进程继承了输出管道，只终止bash进程时读取输出会一直阻塞
+				if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+					log.Printf("Kill process group %d failed, err: %v", cmd.Process.Pid, err)
+				}
+			case <-stop:
+			}
+		}()
+	}
 	wg.Go(
 		func() {
 			forwardStream(outStream, func(line string) {
@@ -108,5 +151,10 @@ func RunCommandWithOutput(cmdline string, projPath string) (string, string, erro
 		},
 	)
 	wg.Wait()
+	// 与之前的行为保持一致，命令的退出码由调用方根据输出判断
+	_ = cmd.Wait()
+	if err := ctx.Err(); err != nil {
+		return stdout, stderr, err
+	}
 	return stdout, stderr, nil
 }
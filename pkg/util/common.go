@@ -150,6 +150,10 @@ func FindGinkgoVersion(path string) (ginkgoVersion int) {
 	if err != nil {
 		fmt.Printf("Error searching for test files: %v\n", err)
 	}
+	if ginkgoVersion == 0 {
+		// 测试文件中没有直接导入ginkgo(例如通过辅助包间接使用)时，以所属模块go.mod中依赖的版本为准
+		ginkgoVersion = ModuleGinkgoVersion(path)
+	}
 	return ginkgoVersion
 }
 
@@ -179,22 +183,158 @@ func checkGinkgoImportVersion(file string) int {
 	return version
 }
 
+// GetSuiteFileNameInPackage 返回包内测试套入口(调用RunSpecs)所在的文件名
 func GetSuiteFileNameInPackage(p string) (string, error) {
-	f, err := os.Open(p)
+	return FindSuiteFile(p)
+}
+
+// FindGoModule 从指定目录开始逐级向上查找go.mod文件，返回模块根目录以及go.mod中声明的模块路径
+func FindGoModule(dir string) (string, string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get abs path of %s", dir)
+	}
+	for {
+		goMod := filepath.Join(absDir, "go.mod")
+		if data, err := os.ReadFile(goMod); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSpace(line)
+				if strings.HasPrefix(line, "module") {
+					modulePath := strings.TrimSpace(strings.TrimPrefix(line, "module"))
+					return absDir, strings.Trim(modulePath, "\"`"), nil
+				}
+			}
+			return "", "", errors.Errorf("no module declared in %s", goMod)
+		}
+		parent := filepath.Dir(absDir)
+		if parent == absDir {
+			return "", "", errors.Errorf("can't find go.mod from %s", dir)
+		}
+		absDir = parent
+	}
+}
+
+// NewReportDir 为单次dry run或者执行创建独立的目录，用于存放json/xml报告等中间文件，返回目录以及清理函数
+// 通过环境变量TESTSOLAR_TTP_ARTIFACTDIR指定目录(相对路径相对于用例库根目录)时，文件会作为调试产物保留在该目录下，清理函数不会删除它们
+func NewReportDir(projPath, prefix string) (string, func(), error) {
+	pattern := strings.NewReplacer(string(os.PathSeparator), "_", ".", "_").Replace(prefix) + "-*"
+	artifactDir := os.Getenv("TESTSOLAR_TTP_ARTIFACTDIR")
+	if artifactDir == "" {
+		dir, err := os.MkdirTemp("", pattern)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "failed to create report dir")
+		}
+		return dir, func() {
+			if err := os.RemoveAll(dir); err != nil {
+				log.Printf("remove report dir %s failed, err: %v", dir, err)
+			}
+		}, nil
+	}
+	if !filepath.IsAbs(artifactDir) {
+		artifactDir = filepath.Join(projPath, artifactDir)
+	}
+	if err := os.MkdirAll(artifactDir, 0755); err != nil {
+		return "", nil, errors.Wrapf(err, "failed to create artifact dir %s", artifactDir)
+	}
+	dir, err := os.MkdirTemp(artifactDir, pattern)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to create report dir in %s", artifactDir)
+	}
+	return dir, func() {
+		log.Printf("Keep report files in %s", dir)
+	}, nil
+}
+
+// FindGoWork 从指定目录开始逐级向上查找go.work文件，返回go.work文件路径以及其中use指令声明的模块目录(绝对路径)
+func FindGoWork(dir string) (string, []string, error) {
+	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to open %s", p)
+		return "", nil, errors.Wrapf(err, "failed to get abs path of %s", dir)
 	}
-	defer f.Close()
+	for {
+		goWork := filepath.Join(absDir, "go.work")
+		if data, err := os.ReadFile(goWork); err == nil {
+			var useDirs []string
+			inBlock := false
+			for _, line := range strings.Split(string(data), "\n") {
+				if i := strings.Index(line, "//"); i >= 0 {
+					line = line[:i]
+				}
+				line = strings.TrimSpace(line)
+				switch {
+				case inBlock && line == ")":
+					inBlock = false
+				case inBlock && line != "":
+					useDirs = append(useDirs, filepath.Join(absDir, strings.Trim(line, "\"`")))
+				case line == "use (":
+					inBlock = true
+				case strings.HasPrefix(line, "use "):
+					useDirs = append(useDirs, filepath.Join(absDir, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use")), "\"`")))
+				}
+			}
+			return goWork, useDirs, nil
+		}
+		parent := filepath.Dir(absDir)
+		if parent == absDir {
+			return "", nil, errors.Errorf("can't find go.work from %s", dir)
+		}
+		absDir = parent
+	}
+}
 
-	files, err := f.Readdir(-1)
+// PackageModule 用例包所属的go模块，用于在模块根目录下编译用例包
+type PackageModule struct {
+	Root        string            // 模块根目录，找不到go.mod时为用例库根目录
+	PackagePath string            // 用例包相对于模块根目录的路径
+	Envs        map[string]string // 在模块根目录下执行go命令时需要额外设置的环境变量
+}
+
+// FindPackageModule 查找用例包所属的go模块，packagePath为相对于projPath的包路径
+// 若存在go.work但其中没有声明该模块，则需要关闭workspace模式，否则go命令会拒绝编译该模块下的包
+func FindPackageModule(projPath, packagePath string) *PackageModule {
+	packageDir := filepath.Join(projPath, packagePath)
+	modRoot, _, err := FindGoModule(packageDir)
+	if err != nil {
+		log.Printf("find go module of %s failed, use %s as module root, err: %v", packageDir, projPath, err)
+		return &PackageModule{Root: projPath, PackagePath: packagePath, Envs: map[string]string{}}
+	}
+	relPath, err := filepath.Rel(modRoot, packageDir)
 	if err != nil {
-		return "", errors.Wrapf(err, "failed to read %s", p)
+		return &PackageModule{Root: projPath, PackagePath: packagePath, Envs: map[string]string{}}
+	}
+	module := &PackageModule{Root: modRoot, PackagePath: relPath, Envs: map[string]string{}}
+	if os.Getenv("GOWORK") != "" {
+		return module
+	}
+	if goWork, useDirs, err := FindGoWork(modRoot); err == nil && !ElementIsInSlice(modRoot, useDirs) {
+		log.Printf("module %s is not used in %s, disable workspace mode", modRoot, goWork)
+		module.Envs["GOWORK"] = "off"
 	}
+	return module
+}
 
-	for _, file := range files {
-		if strings.HasSuffix(file.Name(), "_suite_test.go") {
-			return file.Name(), nil
+// ModuleGinkgoVersion 读取路径所属模块go.mod中依赖的ginkgo版本，同时依赖v1与v2时返回2，未依赖ginkgo时返回0
+func ModuleGinkgoVersion(path string) int {
+	modRoot, _, err := FindGoModule(path)
+	if err != nil {
+		return 0
+	}
+	data, err := os.ReadFile(filepath.Join(modRoot, "go.mod"))
+	if err != nil {
+		return 0
+	}
+	version := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require "))
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "github.com/onsi/ginkgo/v2":
+			return 2
+		case "github.com/onsi/ginkgo":
+			version = 1
 		}
 	}
-	return "", errors.New("Can't find suite test file")
+	return version
 }
@@ -3,6 +3,7 @@ package util
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -153,3 +154,61 @@ func TestFindGinkgoVersion(t *testing.T) {
 	version := FindGinkgoVersion(testdata)
 	assert.Equal(t, version, 2)
 }
+
+func TestFindGoModule(t *testing.T) {
+	testdata, err := filepath.Abs("../../testdata/")
+	assert.NoError(t, err)
+	modRoot, modPath, err := FindGoModule(filepath.Join(testdata, "demo", "book"))
+	assert.NoError(t, err)
+	assert.Equal(t, testdata, modRoot)
+	assert.Equal(t, "testdata", modPath)
+	_, _, err = FindGoModule("/")
+	assert.Error(t, err)
+}
+
+func TestNewReportDir(t *testing.T) {
+	t.Setenv("TESTSOLAR_TTP_ARTIFACTDIR", "")
+	dir, cleanup, err := NewReportDir(t.TempDir(), "dryrun-demo/book")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(filepath.Base(dir), "dryrun-demo_book-"))
+	cleanup()
+	exists, _ := FileExists(dir)
+	assert.False(t, exists)
+
+	projPath := t.TempDir()
+	t.Setenv("TESTSOLAR_TTP_ARTIFACTDIR", "artifacts")
+	dir, cleanup, err = NewReportDir(projPath, "run-demo")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(projPath, "artifacts"), filepath.Dir(dir))
+	cleanup()
+	exists, _ = FileExists(dir)
+	assert.True(t, exists)
+}
+
+func TestFindPackageModule(t *testing.T) {
+	projPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projPath, "sub", "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projPath, "go.mod"), []byte("module example.com/root\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projPath, "sub", "go.mod"), []byte("module example.com/sub\n\nrequire github.com/onsi/ginkgo v1.16.5\n"), 0644))
+	t.Setenv("GOWORK", "")
+
+	module := FindPackageModule(projPath, "sub/pkg")
+	assert.Equal(t, filepath.Join(projPath, "sub"), module.Root)
+	assert.Equal(t, "pkg", module.PackagePath)
+	assert.Len(t, module.Envs, 0)
+	assert.Equal(t, 1, ModuleGinkgoVersion(filepath.Join(projPath, "sub", "pkg")))
+	assert.Equal(t, 0, ModuleGinkgoVersion(projPath))
+
+	// go.work未声明sub模块时关闭workspace模式
+	require.NoError(t, os.WriteFile(filepath.Join(projPath, "go.work"), []byte("go 1.19\n\nuse (\n\t. // root\n)\n"), 0644))
+	goWork, useDirs, err := FindGoWork(filepath.Join(projPath, "sub", "pkg"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(projPath, "go.work"), goWork)
+	assert.Equal(t, []string{projPath}, useDirs)
+	module = FindPackageModule(projPath, "sub/pkg")
+	assert.Equal(t, "off", module.Envs["GOWORK"])
+
+	require.NoError(t, os.WriteFile(filepath.Join(projPath, "go.work"), []byte("go 1.19\n\nuse (\n\t.\n\t./sub\n)\n"), 0644))
+	module = FindPackageModule(projPath, "sub/pkg")
+	assert.Len(t, module.Envs, 0)
+}
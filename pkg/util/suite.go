@@ -0,0 +1,309 @@
+package util
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// runSpecsFuncs ginkgo测试套的入口函数
+var runSpecsFuncs = map[string]bool{
+	"RunSpecs":                              true,
+	"RunSpecsWithDefaultAndCustomReporters": true,
+	"RunSpecsWithCustomReporters":           true,
+}
+
+// testFileInfo 测试文件中与测试套识别相关的信息
+type testFileInfo struct {
+	callRunSpecs  bool
+	importsGinkgo bool
+	// goTests 未调用RunSpecs的标准go测试函数名
+	goTests []string
+	// testifyEntries 通过suite.Run执行testify测试套的测试函数名
+	testifyEntries []string
+}
+
+// IsGoTestFunc 判断函数是否为`func TestXxx(t *testing.T)`形式的标准go测试函数
+func IsGoTestFunc(fn *ast.FuncDecl) bool {
+	if fn.Recv != nil || fn.Body == nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+		return false
+	}
+	// 与go test的规则一致，Test之后的首字母不能为小写字母
+	if suffix := strings.TrimPrefix(fn.Name.Name, "Test"); suffix != "" {
+		if r, _ := utf8.DecodeRuneInString(suffix); unicode.IsLower(r) {
+			return false
+		}
+	}
+	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 || len(fn.Type.Params.List[0].Names) > 1 {
+		return false
+	}
+	star, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "T"
+}
+
+// CallsRunSpecs 判断节点内是否调用了ginkgo测试套的入口函数
+func CallsRunSpecs(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			found = runSpecsFuncs[fun.Name]
+		case *ast.SelectorExpr:
+			found = runSpecsFuncs[fun.Sel.Name]
+		}
+		return true
+	})
+	return found
+}
+
+func inspectTestFile(fset *token.FileSet, file string) (*testFileInfo, error) {
+	node, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	info := &testFileInfo{}
+	for _, importSpec := range node.Imports {
+		if importSpec.Path.Value == "\"github.com/onsi/ginkgo/v2\"" || importSpec.Path.Value == "\"github.com/onsi/ginkgo\"" {
+			info.importsGinkgo = true
+		}
+	}
+	if info.importsGinkgo {
+		info.callRunSpecs = CallsRunSpecs(node)
+	}
+	testifyImportName := testifySuiteImportName(node)
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !IsGoTestFunc(fn) {
+			continue
+		}
+		if info.importsGinkgo && CallsRunSpecs(fn.Body) {
+			continue
+		}
+		if len(testifySuiteRuns(fn.Body, testifyImportName)) > 0 {
+			info.testifyEntries = append(info.testifyEntries, fn.Name.Name)
+			continue
+		}
+		info.goTests = append(info.goTests, fn.Name.Name)
+	}
+	return info, nil
+}
+
+// testFilesInDir 返回目录下的测试文件，以_suite_test.go结尾的文件排在前面
+func testFilesInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", dir)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), "_test.go") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.HasSuffix(files[i], "_suite_test.go") && !strings.HasSuffix(files[j], "_suite_test.go")
+	})
+	return files, nil
+}
+
+// testDirInfo 目录下测试文件的汇总信息
+type testDirInfo struct {
+	// suiteFile 调用了RunSpecs的文件名
+	suiteFile     string
+	importsGinkgo bool
+	// goTests 标准go测试函数名到所在文件名的映射
+	goTests map[string]string
+	// testifyEntries 执行testify测试套的测试函数名到所在文件名的映射
+	testifyEntries map[string]string
+}
+
+// inspectTestDir 检查目录下的所有测试文件，汇总测试套入口以及标准go测试函数
+func inspectTestDir(dir string) (*testDirInfo, error) {
+	files, err := testFilesInDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	dirInfo := &testDirInfo{goTests: map[string]string{}, testifyEntries: map[string]string{}}
+	for _, file := range files {
+		info, err := inspectTestFile(fset, filepath.Join(dir, file))
+		if err != nil {
+			log.Printf("parse test file %s failed, err: %v", filepath.Join(dir, file), err)
+			continue
+		}
+		if info.callRunSpecs && dirInfo.suiteFile == "" {
+			dirInfo.suiteFile = file
+		}
+		dirInfo.importsGinkgo = dirInfo.importsGinkgo || info.importsGinkgo
+		for _, name := range info.goTests {
+			dirInfo.goTests[name] = file
+		}
+		for _, name := range info.testifyEntries {
+			dirInfo.testifyEntries[name] = file
+		}
+	}
+	return dirInfo, nil
+}
+
+// FindSuiteFile 返回包内调用RunSpecs的测试文件名，RunSpecs可以位于任意测试文件或者外部测试包中
+func FindSuiteFile(dir string) (string, error) {
+	dirInfo, err := inspectTestDir(dir)
+	if err != nil {
+		return "", err
+	}
+	file := dirInfo.suiteFile
+	if file == "" {
+		return "", errors.Errorf("can't find RunSpecs in test files of %s", dir)
+	}
+	return file, nil
+}
+
+// testPackageDir 遍历得到的目录及其测试文件的汇总信息
+type testPackageDir struct {
+	path string
+	info *testDirInfo
+}
+
+// skipWalkDir 与go命令匹配包的规则一致，遍历时跳过testdata、vendor以及以.开头的目录(包括.testtool)
+func skipWalkDir(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".")
+}
+
+// scanTestDirs 遍历rootPath下的所有目录(绝对路径)并汇总各目录下的测试文件，rootPath本身不会被跳过
+// 查找测试套与查找标准go测试的目录共用同一次遍历和解析的结果
+func scanTestDirs(rootPath string) ([]*testPackageDir, error) {
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get abs path of %s", rootPath)
+	}
+	var dirs []*testPackageDir
+	err = filepath.WalkDir(rootPath, func(path string, d fs.DirEntry, e error) error {
+		if e != nil {
+			return errors.Wrapf(e, "failed to walk %s", path)
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != rootPath && skipWalkDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		dirInfo, err := inspectTestDir(path)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, &testPackageDir{path: path, info: dirInfo})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+// suitePackages 从遍历结果中筛选调用了RunSpecs的目录以及导入了ginkgo但没有调用RunSpecs的目录
+func suitePackages(dirs []*testPackageDir) (suiteDirs []string, noEntryDirs []string) {
+	for _, dir := range dirs {
+		if dir.info.suiteFile != "" {
+			suiteDirs = append(suiteDirs, dir.path)
+		} else if dir.info.importsGinkgo {
+			noEntryDirs = append(noEntryDirs, dir.path)
+		}
+	}
+	return suiteDirs, noEntryDirs
+}
+
+// goTestPackages 从遍历结果中筛选包含标准go测试函数或者testify测试套的目录
+func goTestPackages(dirs []*testPackageDir) []string {
+	var testDirs []string
+	for _, dir := range dirs {
+		if len(dir.info.goTests) > 0 || len(dir.info.testifyEntries) > 0 {
+			testDirs = append(testDirs, dir.path)
+		}
+	}
+	return testDirs
+}
+
+// FindSuitePackages 遍历rootPath查找ginkgo测试套所在的目录(绝对路径)，以包内测试文件中是否调用RunSpecs为准
+// 导入了ginkgo但没有调用RunSpecs的目录无法编译执行，在noEntryDirs中单独返回
+func FindSuitePackages(rootPath string) (suiteDirs []string, noEntryDirs []string, err error) {
+	dirs, err := scanTestDirs(rootPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	suiteDirs, noEntryDirs = suitePackages(dirs)
+	return suiteDirs, noEntryDirs, nil
+}
+
+// FindGoTests 返回包内标准go测试函数名到所在文件名的映射，调用RunSpecs的ginkgo入口函数以及testify测试套的入口函数不包含在内
+func FindGoTests(dir string) (map[string]string, error) {
+	dirInfo, err := inspectTestDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return dirInfo.goTests, nil
+}
+
+// PackageImportsGinkgo 判断包内是否存在导入了ginkgo的测试文件
+func PackageImportsGinkgo(dir string) (bool, error) {
+	dirInfo, err := inspectTestDir(dir)
+	if err != nil {
+		return false, err
+	}
+	return dirInfo.importsGinkgo, nil
+}
+
+// FindGoTestPackages 遍历rootPath查找包含标准go测试函数或者testify测试套的目录(绝对路径)
+func FindGoTestPackages(rootPath string) ([]string, error) {
+	dirs, err := scanTestDirs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	return goTestPackages(dirs), nil
+}
+
+// FindTestPackages 返回rootPath下需要编译执行的包目录(绝对路径)，包括ginkgo测试套以及包含标准go测试函数的包
+func FindTestPackages(rootPath string) (testDirs []string, noEntryDirs []string, err error) {
+	dirs, err := scanTestDirs(rootPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	suiteDirs, noEntryDirs := suitePackages(dirs)
+	goTestDirs := goTestPackages(dirs)
+	seen := map[string]bool{}
+	for _, dir := range append(suiteDirs, goTestDirs...) {
+		if !seen[dir] {
+			seen[dir] = true
+			testDirs = append(testDirs, dir)
+		}
+	}
+	sort.Strings(testDirs)
+	// 同时包含标准go测试函数的包仍然可以编译执行，不再视为无入口的包
+	var skipped []string
+	for _, dir := range noEntryDirs {
+		if !seen[dir] {
+			skipped = append(skipped, dir)
+		}
+	}
+	return testDirs, skipped, nil
+}
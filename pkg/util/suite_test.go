@@ -0,0 +1,138 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSuiteTestFile(t *testing.T, dir, name, content string) {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestFindSuitePackages(t *testing.T) {
+	root := t.TempDir()
+	// 外部测试包中通过包名调用RunSpecs，且文件名不以_suite_test.go结尾
+	writeSuiteTestFile(t, filepath.Join(root, "entry"), "main_test.go", `package entry_test
+
+import (
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+)
+
+func TestEntry(t *testing.T) {
+	ginkgo.RunSpecs(t, "Entry Suite")
+}
+`)
+	writeSuiteTestFile(t, filepath.Join(root, "entry"), "entry_test.go", `package entry_test
+
+import . "github.com/onsi/ginkgo/v2"
+
+var _ = It("works", func() {})
+`)
+	writeSuiteTestFile(t, filepath.Join(root, "noentry"), "specs_test.go", `package noentry
+
+import . "github.com/onsi/ginkgo"
+
+var _ = It("never runs", func() {})
+`)
+	writeSuiteTestFile(t, filepath.Join(root, "plain"), "plain_test.go", `package plain
+
+import "testing"
+
+func TestPlain(t *testing.T) {}
+`)
+	writeSuiteTestFile(t, filepath.Join(root, ".testtool", "cache"), "cached_suite_test.go", `package cache
+
+import . "github.com/onsi/ginkgo/v2"
+
+func TestCache(t *testing.T) { RunSpecs(t, "Cache") }
+`)
+	// 与go命令一致，testdata、vendor以及以.开头的目录下的测试套不会被查找
+	for _, dir := range []string{"testdata", "vendor", filepath.Join("entry", ".hidden")} {
+		writeSuiteTestFile(t, filepath.Join(root, dir), "skipped_suite_test.go", `package skipped
+
+import . "github.com/onsi/ginkgo/v2"
+
+func TestSkipped(t *testing.T) { RunSpecs(t, "Skipped") }
+`)
+	}
+	suiteDirs, noEntryDirs, err := FindSuitePackages(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "entry")}, suiteDirs)
+	assert.Equal(t, []string{filepath.Join(root, "noentry")}, noEntryDirs)
+	// 遍历的根目录本身不会被跳过
+	suiteDirs, _, err = FindSuitePackages(filepath.Join(root, "testdata"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "testdata")}, suiteDirs)
+
+	suiteFile, err := FindSuiteFile(filepath.Join(root, "entry"))
+	assert.NoError(t, err)
+	assert.Equal(t, "main_test.go", suiteFile)
+	_, err = FindSuiteFile(filepath.Join(root, "noentry"))
+	assert.Error(t, err)
+}
+
+func TestFindGoTestPackages(t *testing.T) {
+	root := t.TempDir()
+	writeSuiteTestFile(t, filepath.Join(root, "mixed"), "mixed_suite_test.go", `package mixed
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func TestMixed(t *testing.T) {
+	RunSpecs(t, "Mixed")
+}
+`)
+	writeSuiteTestFile(t, filepath.Join(root, "mixed"), "plain_test.go", `package mixed
+
+import "testing"
+
+func TestPlain(t *testing.T) {}
+
+func Testlower(t *testing.T) {}
+
+func TestMain(m *testing.M) {}
+
+func BenchmarkPlain(b *testing.B) {}
+`)
+	writeSuiteTestFile(t, filepath.Join(root, "noentry"), "specs_test.go", `package noentry
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = It("never runs", func() {})
+
+func TestHelper(t *testing.T) {}
+`)
+	writeSuiteTestFile(t, filepath.Join(root, "onlyspecs"), "specs_test.go", `package onlyspecs
+
+import . "github.com/onsi/ginkgo/v2"
+
+var _ = It("never runs", func() {})
+`)
+	goTests, err := FindGoTests(filepath.Join(root, "mixed"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"TestPlain": "plain_test.go"}, goTests)
+
+	goTestDirs, err := FindGoTestPackages(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "mixed"), filepath.Join(root, "noentry")}, goTestDirs)
+
+	// 包含标准go测试的包即使没有RunSpecs也需要编译执行
+	testDirs, noEntryDirs, err := FindTestPackages(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "mixed"), filepath.Join(root, "noentry")}, testDirs)
+	assert.Equal(t, []string{filepath.Join(root, "onlyspecs")}, noEntryDirs)
+}
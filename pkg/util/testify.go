@@ -0,0 +1,210 @@
+package util
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// testifySuiteImportPath testify测试套所在的包
+const testifySuiteImportPath = "github.com/stretchr/testify/suite"
+
+// TestifySuite 通过`suite.Run(t, new(MySuite))`执行的testify测试套
+type TestifySuite struct {
+	// Name 测试套类型名
+	Name string
+	// Entry 调用suite.Run的测试函数名
+	Entry string
+	// EntryFile 入口函数所在的文件名
+	EntryFile string
+	// Methods 测试套中以Test开头的方法，可以声明在包内任意测试文件中
+	Methods []*TestifyMethod
+}
+
+// TestifyMethod testify测试套中的测试方法
+type TestifyMethod struct {
+	Name   string
+	File   string
+	Line   int
+	Column int
+}
+
+// MethodNames 返回测试套中所有测试方法的名称
+func (s *TestifySuite) MethodNames() []string {
+	var names []string
+	for _, method := range s.Methods {
+		names = append(names, method.Name)
+	}
+	return names
+}
+
+// Method 根据名称查找测试方法，不存在时返回nil
+func (s *TestifySuite) Method(name string) *TestifyMethod {
+	for _, method := range s.Methods {
+		if method.Name == name {
+			return method
+		}
+	}
+	return nil
+}
+
+// testifySuiteImportName 返回文件中testify suite包的引用名，未导入时返回空字符串
+func testifySuiteImportName(file *ast.File) string {
+	for _, importSpec := range file.Imports {
+		path, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil || path != testifySuiteImportPath {
+			continue
+		}
+		if importSpec.Name == nil {
+			return "suite"
+		}
+		if importSpec.Name.Name == "_" || importSpec.Name.Name == "." {
+			return ""
+		}
+		return importSpec.Name.Name
+	}
+	return ""
+}
+
+// suiteTypeName 从`new(MySuite)`、`&MySuite{}`或者`MySuite{}`形式的表达式中获取测试套类型名
+func suiteTypeName(expr ast.Expr, locals map[string]ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		if fun, ok := e.Fun.(*ast.Ident); ok && fun.Name == "new" && len(e.Args) == 1 {
+			if ident, ok := e.Args[0].(*ast.Ident); ok {
+				return ident.Name
+			}
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return suiteTypeName(e.X, nil)
+		}
+	case *ast.CompositeLit:
+		if ident, ok := e.Type.(*ast.Ident); ok {
+			return ident.Name
+		}
+	case *ast.Ident:
+		// 测试套实例先赋值给局部变量再传入suite.Run
+		if value, ok := locals[e.Name]; ok {
+			return suiteTypeName(value, nil)
+		}
+	}
+	return ""
+}
+
+// testifySuiteRuns 返回函数体中通过suite.Run执行的测试套类型名
+func testifySuiteRuns(body *ast.BlockStmt, importName string) []string {
+	if importName == "" || body == nil {
+		return nil
+	}
+	locals := map[string]ast.Expr{}
+	var suiteNames []string
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) == len(node.Rhs) {
+				for i, lhs := range node.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						locals[ident.Name] = node.Rhs[i]
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if len(node.Names) == len(node.Values) {
+				for i, name := range node.Names {
+					locals[name.Name] = node.Values[i]
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Run" || len(node.Args) != 2 {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != importName {
+				return true
+			}
+			if name := suiteTypeName(node.Args[1], locals); name != "" {
+				suiteNames = append(suiteNames, name)
+			}
+		}
+		return true
+	})
+	return suiteNames
+}
+
+// isTestifyMethod 判断方法是否为testify测试套中的测试方法，testify只执行无参数且以Test开头的导出方法
+func isTestifyMethod(fn *ast.FuncDecl) (string, bool) {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 || !strings.HasPrefix(fn.Name.Name, "Test") {
+		return "", false
+	}
+	if fn.Type.Params != nil && len(fn.Type.Params.List) != 0 {
+		return "", false
+	}
+	recvType := fn.Recv.List[0].Type
+	if star, ok := recvType.(*ast.StarExpr); ok {
+		recvType = star.X
+	}
+	ident, ok := recvType.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// FindTestifySuites 静态解析包内的testify测试套，返回的测试套按入口函数所在文件以及声明顺序排列
+func FindTestifySuites(dir string) ([]*TestifySuite, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list test files in %s", dir)
+	}
+	sort.Strings(files)
+	fset := token.NewFileSet()
+	var suites []*TestifySuite
+	suiteByName := map[string]*TestifySuite{}
+	methods := map[string][]*TestifyMethod{}
+	for _, file := range files {
+		node, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", file)
+		}
+		importName := testifySuiteImportName(node)
+		for _, decl := range node.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if typeName, ok := isTestifyMethod(fn); ok {
+				position := fset.Position(fn.Name.Pos())
+				methods[typeName] = append(methods[typeName], &TestifyMethod{
+					Name:   fn.Name.Name,
+					File:   filepath.Base(file),
+					Line:   position.Line,
+					Column: position.Column,
+				})
+				continue
+			}
+			if !IsGoTestFunc(fn) {
+				continue
+			}
+			for _, suiteName := range testifySuiteRuns(fn.Body, importName) {
+				// 同一个测试套被多个入口函数执行时，以第一个入口函数为准
+				if _, ok := suiteByName[suiteName]; ok {
+					continue
+				}
+				suite := &TestifySuite{Name: suiteName, Entry: fn.Name.Name, EntryFile: filepath.Base(file)}
+				suiteByName[suiteName] = suite
+				suites = append(suites, suite)
+			}
+		}
+	}
+	for _, suite := range suites {
+		suite.Methods = methods[suite.Name]
+	}
+	return suites, nil
+}
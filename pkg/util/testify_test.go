@@ -0,0 +1,72 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindTestifySuites(t *testing.T) {
+	root := t.TempDir()
+	writeSuiteTestFile(t, root, "entry_test.go", `package store
+
+import (
+	"testing"
+
+	testifySuite "github.com/stretchr/testify/suite"
+)
+
+func TestStoreSuite(t *testing.T) {
+	s := &StoreSuite{}
+	testifySuite.Run(t, s)
+}
+
+func TestCacheSuite(t *testing.T) {
+	testifySuite.Run(t, new(CacheSuite))
+}
+
+func TestPlain(t *testing.T) {}
+`)
+	writeSuiteTestFile(t, root, "store_test.go", `package store
+
+import "github.com/stretchr/testify/suite"
+
+type StoreSuite struct {
+	suite.Suite
+}
+
+func (s *StoreSuite) SetupTest() {}
+
+func (s *StoreSuite) TestGet() {}
+
+func (s StoreSuite) TestPut() {}
+
+func (s *StoreSuite) TestWithArgs(name string) {}
+
+type CacheSuite struct {
+	suite.Suite
+}
+
+func (s *CacheSuite) TestEvict() {}
+`)
+	suites, err := FindTestifySuites(root)
+	assert.NoError(t, err)
+	assert.Len(t, suites, 2)
+	assert.Equal(t, "StoreSuite", suites[0].Name)
+	assert.Equal(t, "TestStoreSuite", suites[0].Entry)
+	assert.Equal(t, "entry_test.go", suites[0].EntryFile)
+	assert.Equal(t, []string{"TestGet", "TestPut"}, suites[0].MethodNames())
+	assert.Equal(t, "store_test.go", suites[0].Method("TestGet").File)
+	assert.Equal(t, 11, suites[0].Method("TestGet").Line)
+	assert.Nil(t, suites[0].Method("SetupTest"))
+	assert.Equal(t, "CacheSuite", suites[1].Name)
+	assert.Equal(t, []string{"TestEvict"}, suites[1].MethodNames())
+
+	// 调用suite.Run的入口函数不作为标准go测试
+	goTests, err := FindGoTests(root)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"TestPlain": "entry_test.go"}, goTests)
+	goTestDirs, err := FindGoTestPackages(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{root}, goTestDirs)
+}
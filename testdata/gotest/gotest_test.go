@@ -0,0 +1,34 @@
+package gotest
+
+import (
+	"os"
+	"testing"
+)
+
+func add(a, b int) int {
+	return a + b
+}
+
+func TestAdd(t *testing.T) {
+	t.Run("positive numbers", func(t *testing.T) {
+		if add(1, 2) != 3 {
+			t.Fatal("1 + 2 should be 3")
+		}
+	})
+	t.Run("zero", func(t *testing.T) {
+		t.Log("add zero")
+		if add(1, 0) != 1 {
+			t.Fatal("1 + 0 should be 1")
+		}
+	})
+}
+
+func TestReadFile(t *testing.T) {
+	if _, err := os.Stat("gotest_test.go"); err != nil {
+		t.Errorf("test should run in package dir: %v", err)
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	t.Skip("skip on purpose")
+}
@@ -0,0 +1,20 @@
+package shared
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// ItBehavesLikeCache 缓存实现需要满足的公共行为
+func ItBehavesLikeCache(name string, capacity int) {
+	It(fmt.Sprintf("stores values in %s", name), func() {
+		Expect(capacity).To(BeNumerically(">", 0))
+	})
+	Context("when full", func() {
+		It(fmt.Sprintf("evicts %d entries", capacity), func() {})
+	})
+}
+
+var backends = []string{"memory", "disk"}
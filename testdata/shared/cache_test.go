@@ -0,0 +1,27 @@
+package shared
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Cache", func() {
+	ItBehavesLikeCache("lru", 2)
+
+	for _, backend := range backends {
+		Context(backend, func() {
+			ItBehavesLikeCache(backend, 8)
+		})
+	}
+
+	for i, tc := range []struct {
+		name string
+		size int
+	}{
+		{"small", 1},
+		{name: "large", size: 1024},
+	} {
+		It(fmt.Sprintf("#%d allocates %s buffer", i, tc.name), func() {})
+	}
+})
@@ -0,0 +1,39 @@
+package table
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Calculator", func() {
+	DescribeTable("add",
+		func(m, n, expected int) {
+			Expect(m + n).To(Equal(expected))
+		},
+		Entry("small numbers", 1, 2, 3),
+		Entry(nil, 10, 20, 30),
+		Entry(EntryDescription("%d plus %d equals %d"), 3, 4, 7),
+	)
+	DescribeTable("compare", Label("compare"),
+		func(m, n int, expected bool) {
+			Expect(m > n).To(Equal(expected))
+		},
+		EntryDescription("%d > %d is %t"),
+		Entry(nil, 2, 1, true),
+		Entry(nil, 1, 2, false),
+		Entry("equal", Label("equal", "owner:tom"), 1, 1, false),
+	)
+	DescribeTableSubtree("divide",
+		func(m, n int) {
+			It("divides", func() {
+				Expect(n).NotTo(BeZero())
+				Expect(m / n * n).To(BeNumerically("<=", m))
+			})
+			It("keeps sign", func() {
+				Expect(m/n >= 0).To(Equal(m >= 0 == (n > 0)))
+			})
+		},
+		Entry("by one", 5, 1),
+		Entry(nil, 9, 3),
+	)
+})
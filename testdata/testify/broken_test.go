@@ -0,0 +1,25 @@
+package testify
+
+import (
+	"github.com/stretchr/testify/suite"
+)
+
+type BrokenSetupSuite struct {
+	suite.Suite
+}
+
+func (s *BrokenSetupSuite) SetupSuite() {
+	s.FailNow("setup suite failed")
+}
+
+func (s *BrokenSetupSuite) TestNeverRuns() {}
+
+type BrokenTeardownSuite struct {
+	suite.Suite
+}
+
+func (s *BrokenTeardownSuite) TearDownSuite() {
+	s.Fail("teardown suite failed")
+}
+
+func (s *BrokenTeardownSuite) TestPasses() {}
@@ -0,0 +1,20 @@
+package testify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestUserSuite(t *testing.T) {
+	suite.Run(t, new(UserSuite))
+}
+
+func TestBrokenSetupSuite(t *testing.T) {
+	suite.Run(t, &BrokenSetupSuite{})
+}
+
+func TestBrokenTeardownSuite(t *testing.T) {
+	s := new(BrokenTeardownSuite)
+	suite.Run(t, s)
+}
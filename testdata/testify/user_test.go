@@ -0,0 +1,28 @@
+package testify
+
+import (
+	"github.com/stretchr/testify/suite"
+)
+
+type UserSuite struct {
+	suite.Suite
+	users map[string]bool
+}
+
+func (s *UserSuite) SetupTest() {
+	s.users = map[string]bool{"alice": true}
+}
+
+func (s *UserSuite) TestCreate() {
+	s.users["bob"] = true
+	s.Len(s.users, 2)
+}
+
+func (s *UserSuite) TestDelete() {
+	delete(s.users, "alice")
+	s.Empty(s.users)
+}
+
+func (s *UserSuite) TestFail() {
+	s.Equal(1, 2, "users should not be equal")
+}